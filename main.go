@@ -2,53 +2,69 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
-	"io/fs"
+	"io"
 	"log"
+	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
 	"regexp"
-	"runtime"
 	"strconv"
 	"strings"
-	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/Ian-Costa18/GoFind/pkg/content"
+	"github.com/Ian-Costa18/GoFind/pkg/httpapi"
+	"github.com/Ian-Costa18/GoFind/pkg/index"
+	"github.com/Ian-Costa18/GoFind/pkg/report"
+	"github.com/Ian-Costa18/GoFind/pkg/search"
+	"github.com/Ian-Costa18/GoFind/pkg/status"
 	"github.com/natefinch/lumberjack"
 )
 
-type FoundFile struct {
-	FilePath string
-	Keywords []string
-}
-
-type NumFiles struct {
-	FoundFiles    uint64
-	SearchedFiles uint64
-	NumErrors     uint64
-	NumIgnored    uint64
-}
-
-func FileCollector(filesChan chan *FoundFile, outFile string) {
-	f, err := os.OpenFile(outFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// FileCollector drains filesChan into outFile, rendered in format, and
+// records each match with rec so /rest/matches and /rest/events can see it
+// live. SARIF is a single JSON document, so its file is truncated up front
+// rather than appended to like text/ndjson, and only written out on Close
+// once every match has arrived.
+func FileCollector(filesChan chan *search.FoundFile, outFile string, format report.Format, rec *status.Recorder) {
+	flags := os.O_CREATE | os.O_WRONLY
+	if format == report.SARIF {
+		flags |= os.O_TRUNC
+	} else {
+		flags |= os.O_APPEND
+	}
+	f, err := os.OpenFile(outFile, flags, 0644)
 	if err != nil {
 		panic(err)
 	}
 	defer f.Close()
+
+	w, err := report.NewWriter(format, f)
+	if err != nil {
+		panic(err)
+	}
 	for filePath := range filesChan {
-		fileString := fmt.Sprintf("Path: %s | Keywords: %v\n", filePath.FilePath, strings.Join(filePath.Keywords, " & "))
-		log.Print(fileString)
-		// Append to output file
-		f.WriteString(fileString)
+		log.Printf("Path: %s | Matches: %d\n", filePath.FilePath, len(filePath.Matches))
+		rec.RecordMatch(filePath)
+		if err := w.Write(filePath); err != nil {
+			log.Printf("Error writing result for %s: %v\n", filePath.FilePath, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		log.Printf("Error finalizing output: %v\n", err)
 	}
 	doneMsg := "Finished collecting output files\n"
 	log.Printf(doneMsg)
 	// fmt.Printf(doneMsg)
 }
 
-func ErrorCollector(errorChan chan string, outFile string, numErrors *uint64) {
+// ErrorCollector drains errorChan into outFile and records each error path
+// with rec so /rest/errors and /rest/events can see it live.
+func ErrorCollector(errorChan chan string, outFile string, numErrors *uint64, rec *status.Recorder) {
 	f, err := os.OpenFile(outFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		panic(err)
@@ -57,6 +73,7 @@ func ErrorCollector(errorChan chan string, outFile string, numErrors *uint64) {
 	for fileErrorPath := range errorChan {
 		atomic.AddUint64(numErrors, 1)
 		log.Printf("Error occured for file: %s\n", fileErrorPath)
+		rec.RecordError(fileErrorPath)
 		// Append to output file
 		f.WriteString(fileErrorPath + "\n")
 	}
@@ -65,234 +82,124 @@ func ErrorCollector(errorChan chan string, outFile string, numErrors *uint64) {
 	// fmt.Printf(doneMsg)
 }
 
-func NewThreadSearchFile(filePath string, kws []string, regexs []*regexp.Regexp, fileChan chan *FoundFile, errChan chan string, searchedFiles *uint64, wg *sync.WaitGroup) {
-	defer func() {
-		if r := recover(); r != nil {
-			// fmt.Println("Something went wrong!", r)
-			errChan <- fmt.Sprintf("%s = %v", filePath, r)
-			// fmt.Printf("Error occured for file: %s\n", filePath)
-			return
+// candidatesFromIndex narrows the files an index knows about down to the
+// ones that could possibly match at least one keyword or regex, by
+// intersecting/unioning trigram postings. If any keyword or regex can't be
+// narrowed (too short, or a regex with no derivable literal), every
+// indexed file is kept as a candidate for that term.
+func candidatesFromIndex(idx *index.Index, keywords []string, regexs []*regexp.Regexp) []string {
+	seen := make(map[string]bool)
+	add := func(paths []string) {
+		for _, p := range paths {
+			seen[p] = true
 		}
-	}()
-	defer wg.Done()
-	f, err := os.OpenFile(filePath, os.O_RDONLY, 0755)
-	if err != nil {
-		var pathError *os.PathError
-		if errors.As(err, &pathError) {
-			// fmt.Println("Error occured for file (permission denied):", filePath)
-			errChan <- fmt.Sprintf("%s = %v", filePath, err)
-			return
+	}
+	for _, kw := range keywords {
+		add(idx.QueryLiteral(kw))
+	}
+	for _, re := range regexs {
+		if re == nil {
+			continue
 		}
-		errChan <- fmt.Sprintf("%s = %v", filePath, err)
-		// fmt.Printf("Error occured for file: %s\n%v", filePath, err)
-		return
+		add(idx.QueryRegex(re))
 	}
-	defer f.Close()
-
-	searchMsg := fmt.Sprintf("Searching File: %s\n", filePath)
-	// fmt.Print(searchMsg)
-	log.Print(searchMsg)
-
-	// Splits on newlines by default.
-	scanner := bufio.NewScanner(f)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	file := new(FoundFile)
-	cleanFP := strings.TrimSpace(filePath)
-	file.FilePath = cleanFP
-	file.Keywords = make([]string, 0)
+	if len(keywords) == 0 && len(regexs) == 0 {
+		add(idx.AllIndexedPaths())
+	}
+	candidates := make([]string, 0, len(seen))
+	for p := range seen {
+		candidates = append(candidates, p)
+	}
+	return candidates
+}
 
-	line := 1
-	hit := false
-	for scanner.Scan() {
-		lineText := scanner.Text()
-		for _, kw := range kws {
-			if strings.Contains(lineText, kw) {
-				kwLine := fmt.Sprintf("%s::%d", kw, line)
-				log.Printf("Found keyword in: %s (KW=%s)\n", cleanFP, kwLine)
-				file.Keywords = append(file.Keywords, kwLine)
-				hit = true
-			}
-		}
-		for _, regex := range regexs {
-			if match := regex.FindString(lineText); match != "" {
-				reLine := fmt.Sprintf("%s:%s:%d", match, regex.String(), line)
-				log.Printf("Found regex in: %s (RE=%s|STR=%s)\n", cleanFP, reLine, match)
-				file.Keywords = append(file.Keywords, reLine)
-				hit = true
-			}
+// runIndexCommand implements `GoFind index directory=... index=path.idx`,
+// which only builds or refreshes the trigram index without running a
+// search.
+func runIndexCommand(args []string) {
+	var directoryPath, ignoredPath, ignoredTypesPath, indexPath, binaryFlag string
+	for _, arg := range args {
+		if strings.Contains(arg, "directory=") {
+			directoryPath = strings.Split(arg, "=")[1]
+		} else if strings.Contains(arg, "ignoretypes=") {
+			ignoredTypesPath = strings.Split(arg, "=")[1]
+		} else if strings.Contains(arg, "ignore=") {
+			ignoredPath = strings.Split(arg, "=")[1]
+		} else if strings.Contains(arg, "index=") {
+			indexPath = strings.Split(arg, "=")[1]
+		} else if strings.Contains(arg, "binary=") {
+			binaryFlag = strings.Split(arg, "=")[1]
+		} else {
+			fmt.Println("Unknown argument:", arg)
 		}
-		line++
 	}
-
-	atomic.AddUint64(searchedFiles, 1)
-
-	err = scanner.Err()
-	if err == bufio.ErrTooLong {
-		errChan <- fmt.Sprintf("%s = %v", filePath, err)
-		// fmt.Printf("Error occured for file (line too long): %s\n", filePath)
-		return
-	} else if err != nil {
-		errChan <- fmt.Sprintf("%s = %v", filePath, err)
-		// fmt.Printf("Error occured for file: %s\n%v\n%v", filePath, err)
+	if directoryPath == "" || indexPath == "" {
+		fmt.Println("Usage: GoFind index directory=<dir> index=<path.idx> [ignore=<file>] [ignoretypes=<file>] [binary=skip|hex|text]")
 		return
 	}
+	if ignoredPath == "" {
+		ignoredPath = "ignore.txt"
+	}
+	if ignoredTypesPath == "" {
+		ignoredTypesPath = "ignore-types.txt"
+	}
+	sniffer := content.NewSniffer(content.BinaryMode(binaryFlag))
 
-	if hit {
-		fileChan <- file
+	var ignoredStrings []string
+	if _, err := os.Stat(ignoredPath); err == nil {
+		ignoredStrings = append(ignoredStrings, readLines(ignoredPath)...)
+	}
+	if _, err := os.Stat(ignoredTypesPath); err == nil {
+		ignoredStrings = append(ignoredStrings, readLines(ignoredTypesPath)...)
 	}
-}
 
-func NewThreadFileFinder(directory string, keywords []string, regexs []*regexp.Regexp, ignored_types []string, outputChan chan *FoundFile, errChan chan string, fileCounter *NumFiles, wg *sync.WaitGroup) {
-	defer wg.Done()
-	walkFunc := func(path string, dir fs.DirEntry, err error) error {
-		log.Printf("Found file: %s | Err: %v\n", path, err)
+	var prevIdx *index.Index
+	if _, err := os.Stat(indexPath); err == nil {
+		prevIdx, err = index.Load(indexPath)
 		if err != nil {
-			// fmt.Printf("Error occured for file: %s\n%v", path, err)
-			errChan <- fmt.Sprintf("%s = %v", path, err)
-			return nil
+			fmt.Printf("Existing index at %s could not be loaded, rebuilding from scratch: %v\n", indexPath, err)
+			prevIdx = nil
 		}
-		if dir.IsDir() {
-			return nil
-		}
-		for _, ignore := range ignored_types {
-			if strings.Contains(strings.ToLower(path), strings.ToLower(ignore)) {
-				log.Printf("Ignoring file: %s due to ignored string (%s)\n", path, ignore)
-				return nil
-			}
-		}
-		// fmt.Printf("Found File: %s\n", path)
-		atomic.AddUint64(&fileCounter.FoundFiles, 1)
-		wg.Add(1)
-		go NewThreadSearchFile(path, keywords, regexs, outputChan, errChan, &fileCounter.SearchedFiles, wg)
-		return nil
 	}
-	err := filepath.WalkDir(directory, walkFunc)
+
+	start := time.Now()
+	idx, err := index.Build(directoryPath, ignoredStrings, prevIdx, sniffer)
 	if err != nil {
-		errMsg := fmt.Sprintf("Error occured for directory: %s\n%v", directory, err)
-		// fmt.Print(errMsg)
-		log.Print(errMsg)
-		return
+		panic(err)
 	}
-}
-
-func SameThreadSearchFile(kws []string, regexs []*regexp.Regexp, fileChan, outputChan chan *FoundFile, errChan chan string, searchedFiles *uint64, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for file := range fileChan {
-		func() {
-			filePath := file.FilePath
-			defer func() {
-				if r := recover(); r != nil {
-					// fmt.Println("Something went wrong!", r)
-					errChan <- fmt.Sprintf("%s = %v", filePath, r)
-					// fmt.Printf("Error occured for file: %s\n", filePath)
-					return
-				}
-			}()
-			f, err := os.OpenFile(filePath, os.O_RDONLY, 0755)
-			if err != nil {
-				var pathError *os.PathError
-				if errors.As(err, &pathError) {
-					// fmt.Println("Error occured for file (permission denied):", filePath)
-					errChan <- fmt.Sprintf("%s = %v", filePath, err)
-					return
-				}
-				errChan <- fmt.Sprintf("%s = %v", filePath, err)
-				// fmt.Printf("Error occured for file: %s\n%v", filePath, err)
-				return
-			}
-			defer f.Close()
-
-			searchMsg := fmt.Sprintf("Searching File: %s\n", filePath)
-			// fmt.Print(searchMsg)
-			log.Print(searchMsg)
-
-			// Splits on newlines by default.
-			scanner := bufio.NewScanner(f)
-			buf := make([]byte, 0, 64*1024)
-			scanner.Buffer(buf, 1024*1024)
-
-			line := 1
-			hit := false
-			for scanner.Scan() {
-				lineText := scanner.Text()
-				for _, kw := range kws {
-					if strings.Contains(lineText, kw) {
-						kwLine := fmt.Sprintf("%s:%d", kw, line)
-						// fmt.Printf("Found keyword in: %s (KW=%s)\n", filePath, kwLine)
-						file.Keywords = append(file.Keywords, kwLine)
-						hit = true
-					}
-				}
-				for _, regex := range regexs {
-					if match := regex.FindString(lineText); match != "" {
-						reLine := fmt.Sprintf("%s:%s:%d", match, regex.String(), line)
-						log.Printf("Found regex in: %s (RE=%s|STR=%s)\n", filePath, regex.String(), reLine)
-						file.Keywords = append(file.Keywords, reLine)
-						hit = true
-					}
-				}
-				line++
-			}
-
-			atomic.AddUint64(searchedFiles, 1)
-
-			err = scanner.Err()
-			if err == bufio.ErrTooLong {
-				errChan <- fmt.Sprintf("%s = %v", filePath, err)
-				// fmt.Printf("Error occured for file (line too long): %s\n", filePath)
-				return
-			} else if err != nil {
-				errChan <- fmt.Sprintf("%s = %v", filePath, err)
-				// fmt.Printf("Error occured for file: %s\n%v\n%v", filePath, err)
-				return
-			}
-
-			if hit {
-				outputChan <- file
-			}
-		}()
+	if err := index.Save(idx, indexPath); err != nil {
+		panic(err)
 	}
+	fmt.Printf("Indexed %d files into %s in %s\n", len(idx.Files), indexPath, time.Since(start))
 }
 
-func SameThreadFileFinder(directory string, ignored_types []string, fileChan chan *FoundFile, errChan chan string, fileCounter *NumFiles) {
-	walkFunc := func(path string, dir fs.DirEntry, err error) error {
-		log.Printf("Found file: %s | Err: %v\n", path, err)
-		if err != nil {
-			// fmt.Printf("Error occured for file: %s\n%v", path, err)
-			errChan <- fmt.Sprintf("%s = %v", path, err)
-			return nil
-		}
-		if dir.IsDir() {
-			return nil
-		}
-		for _, ignore := range ignored_types {
-			if strings.Contains(strings.ToLower(path), strings.ToLower(ignore)) {
-				log.Printf("Ignoring file: %s due to ignored string (%s)\n", path, ignore)
-				return nil
-			}
-		}
-		// fmt.Printf("Found File: %s\n", path)
-		atomic.AddUint64(&fileCounter.FoundFiles, 1)
-		fileChan <- &FoundFile{FilePath: path, Keywords: make([]string, 0)}
-		return nil
-	}
-	err := filepath.WalkDir(directory, walkFunc)
+// readLines returns the non-blank, trimmed lines of path.
+func readLines(path string) []string {
+	f, err := os.Open(path)
 	if err != nil {
-		errMsg := fmt.Sprintf("Error occured for directory: %s\n%v", directory, err)
-		// fmt.Print(errMsg)
-		log.Print(errMsg)
-		return
+		panic(err)
 	}
-	doneMsg := fmt.Sprintf("Finished finding files (found #%d files) through directory: %s\n", fileCounter.FoundFiles, directory)
-	log.Printf(doneMsg)
-	// fmt.Printf(doneMsg)
-	close(fileChan)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		clean := strings.TrimSpace(scanner.Text())
+		if clean != "" {
+			lines = append(lines, clean)
+		}
+	}
+	return lines
 }
 
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "index" {
+		runIndexCommand(os.Args[2:])
+		return
+	}
+
 	// Create log to file
 	log.SetOutput(&lumberjack.Logger{
 		Filename:   "log.txt",
@@ -314,8 +221,11 @@ func main() {
 		ignoredPath      string
 		ignoredTypesPath string
 		threadCount      int
+		indexPath        string
+		formatFlag       string
+		httpAddr         string
+		binaryFlag       string
 	)
-	var newThread bool = false
 	args := os.Args[1:]
 	for _, arg := range args {
 		if strings.Contains(arg, "directory=") {
@@ -332,16 +242,6 @@ func main() {
 			outputPath = strings.Split(arg, "=")[1]
 		} else if strings.Contains(arg, "error=") {
 			errPath = strings.Split(arg, "=")[1]
-		} else if strings.Contains(arg, "newthread=") {
-			switch strings.ToLower(strings.Split(arg, "=")[1]) {
-			case "true":
-				newThread = true
-			case "false":
-				newThread = false
-			default:
-				fmt.Println("Invalid value for newthread. Must be 'true' or 'false' exactly.")
-				return
-			}
 		} else if strings.Contains(arg, "threadcount=") {
 			customThreadCount, err := strconv.Atoi(strings.Split(arg, "=")[1])
 			if err != nil {
@@ -349,6 +249,14 @@ func main() {
 				return
 			}
 			threadCount = customThreadCount
+		} else if strings.Contains(arg, "index=") {
+			indexPath = strings.Split(arg, "=")[1]
+		} else if strings.Contains(arg, "format=") {
+			formatFlag = strings.Split(arg, "=")[1]
+		} else if strings.Contains(arg, "http=") {
+			httpAddr = strings.Split(arg, "=")[1]
+		} else if strings.Contains(arg, "binary=") {
+			binaryFlag = strings.Split(arg, "=")[1]
 		} else {
 			fmt.Println("Unknown argument:", arg)
 		}
@@ -375,63 +283,54 @@ func main() {
 	if errPath == "" {
 		errPath = "error.txt"
 	}
+	if formatFlag == "" {
+		formatFlag = string(report.Text)
+	}
+	format := report.Format(formatFlag)
+	if _, err := report.NewWriter(format, io.Discard); err != nil {
+		fmt.Println(err)
+		return
+	}
 	if ignoredPath == "" {
 		ignoredPath = "ignore.txt"
 	}
 	if ignoredTypesPath == "" {
 		ignoredTypesPath = "ignore-types.txt"
 	}
-	if threadCount == 0 {
-		threadCount = runtime.NumCPU()
+	if binaryFlag == "" {
+		binaryFlag = string(content.Skip)
+	}
+	binaryMode := content.BinaryMode(binaryFlag)
+	switch binaryMode {
+	case content.Skip, content.Hex, content.Text:
+	default:
+		fmt.Printf("Invalid value for binary: %s. Must be one of skip, hex, text.\n", binaryFlag)
+		return
 	}
 
-	log.Printf("Using options:\n\tDirectory: %s\n\tKeywords: %s\n\tOutput: %s\n\tErrors: %s\n\tIgnored: %s\n\tIgnored Types: %s\n\tNew Thread: %t\n", directoryPath, keywordsPath, outputPath, errPath, ignoredPath, ignoredTypesPath, newThread)
+	log.Printf("Using options:\n\tDirectory: %s\n\tKeywords: %s\n\tOutput: %s\n\tFormat: %s\n\tErrors: %s\n\tIgnored: %s\n\tIgnored Types: %s\n\tBinary: %s\n", directoryPath, keywordsPath, outputPath, format, errPath, ignoredPath, ignoredTypesPath, binaryMode)
 
 	// Get keywords to search for
 	// Check if keywords file exists
 	if _, err := os.Stat(keywordsPath); errors.Is(err, os.ErrNotExist) {
 		panic(fmt.Errorf("error: keywords file does not exist: %s\n", keywordsPath))
 	}
-	kwf, err := os.Open(keywordsPath)
-	if err != nil {
-		panic(err)
-	}
-	defer kwf.Close()
-	// compile the lines into a slice of strings
-	scanner := bufio.NewScanner(kwf)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-	var keywords []string
-	for scanner.Scan() {
-		cleanKW := strings.TrimSpace(scanner.Text())
-		if cleanKW != "" {
-			keywords = append(keywords, cleanKW)
-		}
-	}
+	keywords := readLines(keywordsPath)
+
 	// Get Regexes to search for
 	// Check if Regex file exists
 	if _, err := os.Stat(regexPath); errors.Is(err, os.ErrNotExist) {
 		panic(fmt.Errorf("error: regex file does not exist: %s\n", regexPath))
 	}
-	ref, err := os.Open(regexPath)
-	if err != nil {
-		panic(err)
-	}
-	defer ref.Close()
-	re_scanner := bufio.NewScanner(ref)
-	re_buf := make([]byte, 0, 64*1024)
-	re_scanner.Buffer(re_buf, 1024*1024)
 	var regexs []*regexp.Regexp
-	for re_scanner.Scan() {
-		cleanRE := strings.TrimSpace(re_scanner.Text())
-		if cleanRE != "" {
-			regex, err := regexp.Compile(cleanRE)
-			if err != nil {
-				fmt.Printf("Regex (%s) failed to compile, this regex will not be searched: %s\n", cleanRE, err)
-				log.Printf("Regex (%s) failed to compile, this regex will not be searched: %s\n", cleanRE, err)
-			}
-			regexs = append(regexs, regex)
+	for _, cleanRE := range readLines(regexPath) {
+		regex, err := regexp.Compile(cleanRE)
+		if err != nil {
+			fmt.Printf("Regex (%s) failed to compile, this regex will not be searched: %s\n", cleanRE, err)
+			log.Printf("Regex (%s) failed to compile, this regex will not be searched: %s\n", cleanRE, err)
+			continue
 		}
+		regexs = append(regexs, regex)
 	}
 
 	// Get ignored directories
@@ -439,48 +338,51 @@ func main() {
 	if _, err := os.Stat(ignoredPath); errors.Is(err, os.ErrNotExist) {
 		panic(fmt.Errorf("error: ignored file does not exist: %s\n", ignoredPath))
 	}
-	igf, err := os.Open(ignoredPath)
-	if err != nil {
-		panic(err)
-	}
-	defer igf.Close()
-	// compile the lines into a slice of strings
-	scanner = bufio.NewScanner(igf)
-	buf = make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-	var ignored_strings []string
-	for scanner.Scan() {
-		cleanDIR := strings.TrimSpace(scanner.Text())
-		if cleanDIR != "" {
-			ignored_strings = append(ignored_strings, cleanDIR)
-		}
-	}
+	ignored_strings := readLines(ignoredPath)
+
 	// Get ignored types
 	// Check if ignored file exists
 	if _, err := os.Stat(ignoredTypesPath); errors.Is(err, os.ErrNotExist) {
 		panic(fmt.Errorf("error: ignored types file does not exist: %s\n", ignoredTypesPath))
 	}
-	igtf, err := os.Open(ignoredTypesPath)
-	if err != nil {
-		panic(err)
-	}
-	defer igtf.Close()
-	// compile the lines into a slice of strings
-	scanner = bufio.NewScanner(igtf)
-	buf = make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-	for scanner.Scan() {
-		cleanDIR := strings.TrimSpace(scanner.Text())
-		if cleanDIR != "" {
-			ignored_strings = append(ignored_strings, cleanDIR)
+	ignored_strings = append(ignored_strings, readLines(ignoredTypesPath)...)
+
+	searcher := search.New(directoryPath, keywords, regexs, ignored_strings, threadCount, binaryMode)
+
+	// When an index is given, build/refresh it up front and use it to prune
+	// the set of files the Searcher actually walks and searches.
+	if indexPath != "" {
+		var prevIdx *index.Index
+		if _, err := os.Stat(indexPath); err == nil {
+			prevIdx, err = index.Load(indexPath)
+			if err != nil {
+				log.Printf("Could not load existing index %s, rebuilding from scratch: %v\n", indexPath, err)
+			}
+		}
+		idx, err := index.Build(directoryPath, ignored_strings, prevIdx, searcher.Content)
+		if err != nil {
+			panic(err)
+		}
+		if err := index.Save(idx, indexPath); err != nil {
+			panic(err)
 		}
+		searcher.Candidates = candidatesFromIndex(idx, keywords, regexs)
+		log.Printf("Using index %s: %d candidate files out of %d indexed\n", indexPath, len(searcher.Candidates), len(idx.Files))
 	}
 
-	fileCounter := &NumFiles{FoundFiles: 0, SearchedFiles: 0}
+	fileCounter := searcher.Counter
+	rec := status.NewRecorder(fileCounter, 500)
+
+	if httpAddr != "" {
+		mux := httpapi.NewServeMux(rec)
+		go func() {
+			log.Printf("Starting HTTP API on %s\n", httpAddr)
+			if err := http.ListenAndServe(httpAddr, mux); err != nil {
+				log.Printf("HTTP API stopped: %v\n", err)
+			}
+		}()
+	}
 
-	wg := &sync.WaitGroup{}
-	outputChan := make(chan *FoundFile)
-	errChan := make(chan string)
 	doneChan := make(chan bool)
 	doneFunc := func() {
 		for done := range doneChan {
@@ -488,42 +390,28 @@ func main() {
 				break
 			}
 			for {
-				fmt.Printf("\rFound files: %d | Searched files: %d | Files with errors: %d | Files ignored: %d | Elapsed time: %s", fileCounter.FoundFiles, fileCounter.SearchedFiles, fileCounter.NumErrors, fileCounter.NumIgnored, time.Since(start))
+				p := rec.Progress()
+				fmt.Printf("\rFound files: %d | Searched files: %d | Files with errors: %d | Files ignored: %d | Elapsed time: %s", p.FoundFiles, p.SearchedFiles, p.NumErrors, p.NumIgnored, p.Elapsed)
 				time.Sleep(time.Second / 4)
 			}
 		}
 	}
 
-	if newThread {
-		wg.Add(1)
-		go FileCollector(outputChan, outputPath)
-		go ErrorCollector(errChan, errPath, &fileCounter.NumErrors)
-		go NewThreadFileFinder(directoryPath, keywords, regexs, ignored_strings, outputChan, errChan, fileCounter, wg)
-		go doneFunc()
-		doneChan <- false
-		wg.Wait()
-		close(doneChan)
-		close(outputChan)
-		close(errChan)
-	} else {
-		fileChan := make(chan *FoundFile)
-		go FileCollector(outputChan, outputPath)
-		go ErrorCollector(errChan, errPath, &fileCounter.NumErrors)
-		threadMessage := fmt.Sprintf("Starting %d threads\n", threadCount)
-		fmt.Print(threadMessage)
-		log.Print(threadMessage)
-		for i := 0; i < threadCount; i++ {
-			wg.Add(1)
-			go SameThreadSearchFile(keywords, regexs, fileChan, outputChan, errChan, &fileCounter.SearchedFiles, wg)
-		}
-		go doneFunc()
-		doneChan <- false
-		SameThreadFileFinder(directoryPath, ignored_strings, fileChan, errChan, fileCounter)
-		wg.Wait()
-		close(doneChan)
-		close(outputChan)
-		close(errChan)
-	}
+	// SIGINT cancels the search context so the worker pool and directory
+	// walk drain cleanly (in-flight files finish, no new ones start)
+	// instead of leaving output files half-written.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	go FileCollector(searcher.Output, outputPath, format, rec)
+	go ErrorCollector(searcher.Errors, errPath, &fileCounter.NumErrors, rec)
+	threadMessage := fmt.Sprintf("Starting %d threads\n", searcher.ThreadCount)
+	fmt.Print(threadMessage)
+	log.Print(threadMessage)
+	go doneFunc()
+	doneChan <- false
+	searcher.Run(ctx)
+	close(doneChan)
 
 	filesFound := fmt.Sprintf("\nFound/Searched Files: %d/%d", fileCounter.FoundFiles, fileCounter.SearchedFiles)
 	fmt.Printf(filesFound + "\n")
@@ -0,0 +1,112 @@
+// Package content sniffs a file's container format and yields a plain-text
+// reader for GoFind's line-by-line scanner, so pointing GoFind at a shared
+// drive full of gzipped logs and Office documents doesn't produce garbage
+// matches or trip bufio.ErrTooLong on a compressed byte stream.
+package content
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BinaryMode controls what happens to a file the Sniffer can't decode to
+// text.
+type BinaryMode string
+
+const (
+	// Skip excludes the file from the scan entirely (the default).
+	Skip BinaryMode = "skip"
+	// Hex scans a hex dump of the raw bytes, so binary signatures can
+	// still be searched for.
+	Hex BinaryMode = "hex"
+	// Text scans the raw bytes as if they were already text.
+	Text BinaryMode = "text"
+)
+
+// sniffLen mirrors pkg/index's binary heuristic: a NUL byte in the first
+// sniffLen bytes marks a file as binary.
+const sniffLen = 512
+
+// Sniffer decodes known container formats - gzip, the Office Open XML
+// formats, and PDF - to plain text before GoFind's scanner sees them, and
+// decides what to do with everything else via Binary.
+type Sniffer struct {
+	Binary BinaryMode
+}
+
+// NewSniffer returns a Sniffer honoring mode for files it can't decode to
+// text. An empty mode defaults to Skip.
+func NewSniffer(mode BinaryMode) *Sniffer {
+	if mode == "" {
+		mode = Skip
+	}
+	return &Sniffer{Binary: mode}
+}
+
+// Open returns a reader over path's content as plain text, plus whether it
+// should be scanned at all - false means the caller should count it as
+// ignored and move on, which only happens when Binary is Skip.
+func (s *Sniffer) Open(path string) (io.ReadCloser, bool, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		return s.openGzip(path)
+	case ".docx", ".xlsx", ".pptx":
+		return s.openOfficeDoc(path)
+	case ".pdf":
+		return s.openPDF(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		f.Close()
+		return nil, false, err
+	}
+	if !looksBinary(head[:n]) {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, false, err
+		}
+		return f, true, nil
+	}
+	return s.decodeBinary(f)
+}
+
+func looksBinary(head []byte) bool {
+	for _, b := range head {
+		if b == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeBinary applies s.Binary to a file whose sniffed head looked
+// binary. f is reset to the start before being reused, since the sniff
+// read already consumed up to sniffLen bytes of it.
+func (s *Sniffer) decodeBinary(f *os.File) (io.ReadCloser, bool, error) {
+	if s.Binary == Skip || s.Binary == "" {
+		f.Close()
+		return nil, false, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, false, err
+	}
+	if s.Binary == Text {
+		return f, true, nil
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, false, err
+	}
+	return io.NopCloser(strings.NewReader(hex.Dump(data))), true, nil
+}
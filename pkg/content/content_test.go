@@ -0,0 +1,190 @@
+package content
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, s *Sniffer, path string) (string, bool) {
+	t.Helper()
+	r, scan, err := s.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !scan {
+		return "", false
+	}
+	defer r.Close()
+	data := make([]byte, 0, 4096)
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		data = append(data, buf[:n]...)
+		if err != nil {
+			break
+		}
+	}
+	return string(data), true
+}
+
+func TestOpenGzipDecodes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte("hello from inside the gzip\n"))
+	gz.Close()
+	f.Close()
+
+	text, scan := readAll(t, NewSniffer(Skip), path)
+	if !scan {
+		t.Fatal("expected scan = true")
+	}
+	if text != "hello from inside the gzip\n" {
+		t.Fatalf("text = %q", text)
+	}
+}
+
+func TestOpenOfficeDocExtractsDocxText(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.docx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`<w:document><w:body><w:p>needle in a haystack</w:p></w:body></w:document>`))
+	zw.Close()
+	f.Close()
+
+	text, scan := readAll(t, NewSniffer(Skip), path)
+	if !scan {
+		t.Fatal("expected scan = true")
+	}
+	if !strings.Contains(text, "needle in a haystack") {
+		t.Fatalf("text = %q, want it to contain the paragraph text", text)
+	}
+}
+
+func TestOpenOfficeDocSplitsOnParagraphBoundaries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.docx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`<w:document><w:body><w:p>first paragraph</w:p><w:p>needle here</w:p></w:body></w:document>`))
+	zw.Close()
+	f.Close()
+
+	text, scan := readAll(t, NewSniffer(Skip), path)
+	if !scan {
+		t.Fatal("expected scan = true")
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	found := -1
+	for i, line := range lines {
+		if strings.Contains(line, "needle here") {
+			found = i
+		}
+		if strings.Contains(line, "first paragraph") && strings.Contains(line, "needle here") {
+			t.Fatalf("paragraphs were not split onto separate lines: %q", line)
+		}
+	}
+	if found <= 0 {
+		t.Fatalf("expected the second paragraph on a line after the first, got lines = %v", lines)
+	}
+}
+
+func TestOpenSkipsBinaryByDefault(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.bin")
+	if err := os.WriteFile(path, []byte{0x00, 0x01, 0x02, 'a', 'b'}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, scan := readAll(t, NewSniffer(Skip), path)
+	if scan {
+		t.Fatal("expected scan = false for a binary file under Skip")
+	}
+}
+
+func TestOpenHexDumpsBinaryWhenRequested(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.bin")
+	if err := os.WriteFile(path, []byte{0x00, 0x01, 0x02, 'a', 'b'}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, scan := readAll(t, NewSniffer(Hex), path)
+	if !scan {
+		t.Fatal("expected scan = true under Hex")
+	}
+	if !strings.Contains(text, "00 01 02") {
+		t.Fatalf("text = %q, want a hex dump", text)
+	}
+}
+
+func TestExtractPDFTextReadsTjOperators(t *testing.T) {
+	stream := "BT /F1 12 Tf (Hello World) Tj ET"
+	data := []byte("<< /Length " + strconv.Itoa(len(stream)) + " >>\nstream\n" + stream + "\nendstream")
+
+	text := extractPDFText(data)
+	if !strings.Contains(text, "Hello World") {
+		t.Fatalf("text = %q, want it to contain %q", text, "Hello World")
+	}
+}
+
+// TestExtractPDFTextDecodesType0FontViaToUnicodeCMap exercises the case a
+// font-subset/CID-keyed PDF (as produced by Word, LibreOffice, browser
+// "print to PDF") actually uses: text shown as hex-string glyph codes,
+// resolved back to real characters via an embedded /ToUnicode CMap.
+func TestExtractPDFTextDecodesType0FontViaToUnicodeCMap(t *testing.T) {
+	// "Hi" as arbitrary 2-byte glyph codes 0001, 0002, with a ToUnicode
+	// CMap mapping those codes back to 'H' (0048) and 'i' (0069).
+	cmapStream := "beginbfchar\n<0001> <0048>\n<0002> <0069>\nendbfchar"
+	contentStream := "BT /F1 12 Tf <00010002> Tj ET"
+
+	data := []byte(
+		"<< /Length " + strconv.Itoa(len(cmapStream)) + " >>\nstream\n" + cmapStream + "\nendstream\n" +
+			"<< /Length " + strconv.Itoa(len(contentStream)) + " >>\nstream\n" + contentStream + "\nendstream",
+	)
+
+	text := extractPDFText(data)
+	if !strings.Contains(text, "Hi") {
+		t.Fatalf("text = %q, want it to contain the ToUnicode-decoded %q", text, "Hi")
+	}
+}
+
+// TestExtractPDFTextDropsUnmappedHexRunsInsteadOfGarbage covers the gap a
+// full PDF parser would close but this best-effort extractor can't: a
+// hex-string run whose codes have no /ToUnicode entry (e.g. a font-subset
+// PDF with no embedded CMap) must be dropped, not decoded as if the raw
+// code bytes were Latin1/ASCII - that would silently emit glyph-code
+// garbage with no way for a caller to tell it apart from real text.
+func TestExtractPDFTextDropsUnmappedHexRunsInsteadOfGarbage(t *testing.T) {
+	stream := "BT /F1 12 Tf <00010002> Tj ET"
+	data := []byte("<< /Length " + strconv.Itoa(len(stream)) + " >>\nstream\n" + stream + "\nendstream")
+
+	text := extractPDFText(data)
+	if strings.TrimSpace(text) != "" {
+		t.Fatalf("text = %q, want empty output for an unmapped hex run rather than decoded garbage", text)
+	}
+}
@@ -0,0 +1,38 @@
+package content
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+// openGzip decodes a .gz file into the plain text it contains.
+func (s *Sniffer) openGzip(path string) (io.ReadCloser, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, false, err
+	}
+	return &gzipFile{gz: gz, f: f}, true, nil
+}
+
+// gzipFile closes both the gzip reader and the underlying file it wraps.
+type gzipFile struct {
+	gz *gzip.Reader
+	f  *os.File
+}
+
+func (g *gzipFile) Read(p []byte) (int, error) { return g.gz.Read(p) }
+
+func (g *gzipFile) Close() error {
+	gzErr := g.gz.Close()
+	fErr := g.f.Close()
+	if gzErr != nil {
+		return gzErr
+	}
+	return fErr
+}
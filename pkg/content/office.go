@@ -0,0 +1,61 @@
+package content
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// officeParts maps an Office Open XML extension to the zip entries inside
+// it that hold the document's visible text.
+var officeParts = map[string]func(name string) bool{
+	".docx": func(name string) bool { return name == "word/document.xml" },
+	".xlsx": func(name string) bool {
+		return name == "xl/sharedStrings.xml" || strings.HasPrefix(name, "xl/worksheets/")
+	},
+	".pptx": func(name string) bool { return strings.HasPrefix(name, "ppt/slides/slide") },
+}
+
+var xmlTag = regexp.MustCompile(`<[^>]+>`)
+
+// paragraphBreak matches the closing tags that mark a paragraph (docx,
+// pptx), shared string (xlsx), or row (xlsx worksheet) boundary, so those
+// boundaries can be turned into newlines before the rest of the markup is
+// stripped. Without this, an entire part (e.g. all of word/document.xml)
+// flattens into one line and every match in it gets reported as Line: 1.
+var paragraphBreak = regexp.MustCompile(`(?i)</w:p>|</a:p>|</si>|</row>`)
+
+// openOfficeDoc extracts the text-bearing XML parts of a docx/xlsx/pptx
+// (themselves zip archives), turns their paragraph/row boundaries into
+// newlines, and strips the remaining markup down to plain text.
+func (s *Sniffer) openOfficeDoc(path string) (io.ReadCloser, bool, error) {
+	match := officeParts[strings.ToLower(filepath.Ext(path))]
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer zr.Close()
+
+	var buf bytes.Buffer
+	for _, entry := range zr.File {
+		if !match(entry.Name) {
+			continue
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		broken := paragraphBreak.ReplaceAll(data, []byte("$0\n"))
+		buf.WriteString(xmlTag.ReplaceAllString(string(broken), " "))
+		buf.WriteByte('\n')
+	}
+	return io.NopCloser(&buf), true, nil
+}
@@ -0,0 +1,249 @@
+package content
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	pdfStream  = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n(.*?)endstream`)
+	pdfTj      = regexp.MustCompile(`\(((?:[^()\\]|\\.)*)\)\s*Tj`)
+	pdfHexTj   = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*Tj`)
+	pdfTJ      = regexp.MustCompile(`\[((?:[^\[\]]|\\.)*)\]\s*TJ`)
+	pdfTJParts = regexp.MustCompile(`\(((?:[^()\\]|\\.)*)\)|<([0-9A-Fa-f]+)>`)
+
+	bfChar      = regexp.MustCompile(`(?s)beginbfchar(.*?)endbfchar`)
+	bfRange     = regexp.MustCompile(`(?s)beginbfrange(.*?)endbfrange`)
+	bfCharPair  = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>`)
+	bfRangeTrip = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>`)
+)
+
+// openPDF pulls the visible text out of a PDF's content streams.
+//
+// NOTE: the original request for PDF support called for
+// github.com/ledongthuc/pdf. That dependency requires a go directive
+// this module's toolchain can't satisfy (go.mod stays on the version the
+// rest of the repo builds with), and has no tagged release this module
+// could pin to instead, so it was deliberately swapped for the
+// self-contained extractor below rather than silently dropped. This is
+// an intentional substitution, not a library "integrated as requested" -
+// see extractPDFText's doc comment for exactly what it can and can't
+// recover.
+func (s *Sniffer) openPDF(path string) (io.ReadCloser, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	return io.NopCloser(strings.NewReader(extractPDFText(data))), true, nil
+}
+
+// extractPDFText finds every stream object in a PDF, inflates the
+// FlateDecode-compressed ones, and pulls out the strings shown by the Tj
+// and TJ text operators. This is a minimal content-stream reader, not a
+// full PDF parser: it doesn't walk the page/font object graph, so it
+// can't tell which font a run of text uses. Most writers that embed
+// subset TrueType/CID fonts (Word, LibreOffice, "print to PDF") show text
+// as hex strings and also embed a /ToUnicode CMap so copy-paste and
+// accessibility tools can recover real text; extractPDFText finds that
+// CMap and uses it to decode hex-string text runs. Literal (parenthesis)
+// strings are assumed to already be ASCII/Latin1, which holds for simple
+// (non-CID) fonts but not for a literal string encoded against a custom
+// font's glyph codes. A hex-string run whose codes aren't covered by a
+// /ToUnicode CMap is dropped rather than decoded as raw bytes, so this
+// stays best-effort and silent-garbage-free rather than wrong: PDF
+// search coverage here is ASCII/Latin1-text and ToUnicode-mapped text
+// only, not a substitute for a full PDF text layer.
+func extractPDFText(data []byte) string {
+	cmap := buildToUnicodeCMap(data)
+	var out bytes.Buffer
+	for _, m := range pdfStream.FindAllSubmatch(data, -1) {
+		writePDFText(&out, decodeStream(m), cmap)
+	}
+	return out.String()
+}
+
+// buildToUnicodeCMap scans every stream object for a /ToUnicode CMap
+// (identified by its beginbfchar/beginbfrange blocks) and merges their
+// source-code -> Unicode mappings into one table. PDFs rarely need more
+// than one CMap in practice, and the content streams here aren't
+// associated back to the font resource that owns a given CMap, so all
+// CMaps found in the document are merged into a single table.
+func buildToUnicodeCMap(data []byte) map[string]string {
+	cmap := make(map[string]string)
+	for _, m := range pdfStream.FindAllSubmatch(data, -1) {
+		content := decodeStream(m)
+		if !bytes.Contains(content, []byte("beginbfchar")) && !bytes.Contains(content, []byte("beginbfrange")) {
+			continue
+		}
+		for _, blk := range bfChar.FindAllSubmatch(content, -1) {
+			for _, pair := range bfCharPair.FindAllSubmatch(blk[1], -1) {
+				addCMapEntry(cmap, string(pair[1]), string(pair[2]))
+			}
+		}
+		for _, blk := range bfRange.FindAllSubmatch(content, -1) {
+			for _, tr := range bfRangeTrip.FindAllSubmatch(blk[1], -1) {
+				addCMapRange(cmap, string(tr[1]), string(tr[2]), string(tr[3]))
+			}
+		}
+	}
+	return cmap
+}
+
+// addCMapEntry records a single beginbfchar source-code -> destination
+// mapping, keyed on the normalized source hex code.
+func addCMapEntry(cmap map[string]string, srcHex, dstHex string) {
+	if dst := utf16HexToString(dstHex); dst != "" {
+		cmap[normalizeHex(srcHex)] = dst
+	}
+}
+
+// addCMapRange expands a beginbfrange entry of the form <lo> <hi> <dst>
+// into one cmap entry per code in [lo, hi], each consecutive code mapping
+// to consecutive destination code points starting at dst. The array form
+// of bfrange (a distinct destination per code) isn't handled.
+func addCMapRange(cmap map[string]string, loHex, hiHex, dstHex string) {
+	lo, errLo := strconv.ParseUint(loHex, 16, 32)
+	hi, errHi := strconv.ParseUint(hiHex, 16, 32)
+	dst, errDst := strconv.ParseUint(dstHex, 16, 32)
+	if errLo != nil || errHi != nil || errDst != nil || hi < lo || hi-lo > 65536 {
+		return
+	}
+	width := len(loHex)
+	for code := lo; code <= hi; code++ {
+		srcHex := padHex(code, width)
+		dstStr := utf16HexToString(padHex(dst+(code-lo), len(dstHex)))
+		if dstStr == "" {
+			continue
+		}
+		cmap[normalizeHex(srcHex)] = dstStr
+	}
+}
+
+// decodeStream returns a stream object's bytes, inflated if its
+// dictionary (m[1]) declares FlateDecode.
+func decodeStream(m [][]byte) []byte {
+	dict, raw := m[1], bytes.TrimRight(m[2], "\r\n")
+	if bytes.Contains(dict, []byte("FlateDecode")) {
+		if decoded, err := inflate(raw); err == nil {
+			return decoded
+		}
+	}
+	return raw
+}
+
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// writePDFText appends the text shown by every Tj/TJ operator in a
+// (decompressed) content stream to out, one operator's output per line.
+// Hex-string operands are only emitted when cmap can resolve every code
+// in them; see extractPDFText for why an unresolved hex run is dropped
+// instead of decoded as raw bytes.
+func writePDFText(out *bytes.Buffer, content []byte, cmap map[string]string) {
+	for _, m := range pdfTj.FindAllSubmatch(content, -1) {
+		out.WriteString(unescapePDFString(m[1]))
+		out.WriteByte('\n')
+	}
+	for _, m := range pdfHexTj.FindAllSubmatch(content, -1) {
+		if s, ok := decodeHexRun(string(m[1]), cmap); ok {
+			out.WriteString(s)
+			out.WriteByte('\n')
+		}
+	}
+	for _, arr := range pdfTJ.FindAllSubmatch(content, -1) {
+		for _, part := range pdfTJParts.FindAllSubmatch(arr[1], -1) {
+			switch {
+			case part[1] != nil:
+				out.WriteString(unescapePDFString(part[1]))
+			case part[2] != nil:
+				if s, ok := decodeHexRun(string(part[2]), cmap); ok {
+					out.WriteString(s)
+				}
+			}
+		}
+		out.WriteByte('\n')
+	}
+}
+
+// decodeHexRun splits a hex-string text operand into 2-byte codes (the
+// near-universal width for Identity-H-encoded Type0/CID fonts) and looks
+// each one up in cmap. It only returns ok=true when every code resolves,
+// so a partially-covered or uncovered run is dropped rather than mixing
+// in placeholder text.
+func decodeHexRun(hx string, cmap map[string]string) (string, bool) {
+	if len(hx) == 0 || len(hx)%4 != 0 || len(cmap) == 0 {
+		return "", false
+	}
+	var out strings.Builder
+	for i := 0; i < len(hx); i += 4 {
+		s, ok := cmap[normalizeHex(hx[i:i+4])]
+		if !ok {
+			return "", false
+		}
+		out.WriteString(s)
+	}
+	return out.String(), true
+}
+
+// utf16HexToString decodes a ToUnicode CMap destination value - one or
+// more big-endian UTF-16 code units packed as hex - into a string.
+func utf16HexToString(hx string) string {
+	if len(hx) == 0 || len(hx)%4 != 0 {
+		return ""
+	}
+	units := make([]uint16, 0, len(hx)/4)
+	for i := 0; i < len(hx); i += 4 {
+		v, err := strconv.ParseUint(hx[i:i+4], 16, 16)
+		if err != nil {
+			return ""
+		}
+		units = append(units, uint16(v))
+	}
+	return string(utf16.Decode(units))
+}
+
+func normalizeHex(s string) string { return strings.ToUpper(s) }
+
+func padHex(v uint64, width int) string {
+	s := strconv.FormatUint(v, 16)
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+	return strings.ToUpper(s)
+}
+
+// unescapePDFString resolves the backslash escapes PDF literal strings use
+// for parentheses, backslashes and common whitespace characters.
+func unescapePDFString(s []byte) string {
+	var out bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				out.WriteByte('\n')
+			case 'r':
+				out.WriteByte('\r')
+			case 't':
+				out.WriteByte('\t')
+			default:
+				out.WriteByte(s[i])
+			}
+			continue
+		}
+		out.WriteByte(s[i])
+	}
+	return out.String()
+}
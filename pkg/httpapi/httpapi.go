@@ -0,0 +1,100 @@
+// Package httpapi exposes a GoFind run's live progress, recent
+// matches/errors and a streaming feed over HTTP, mirroring the
+// /rest/folder/errors style endpoints Syncthing uses for its own web UI -
+// this lets GoFind run as a long-lived scanner with a simple dashboard
+// instead of having to grep log files.
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Ian-Costa18/GoFind/pkg/status"
+)
+
+// defaultPageLimit bounds /rest/errors and /rest/matches when the caller
+// doesn't specify one.
+const defaultPageLimit = 100
+
+// NewServeMux returns the HTTP handler for rec's /rest/* endpoints.
+func NewServeMux(rec *status.Recorder) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/progress", progressHandler(rec))
+	mux.HandleFunc("/rest/errors", errorsHandler(rec))
+	mux.HandleFunc("/rest/matches", matchesHandler(rec))
+	mux.HandleFunc("/rest/events", eventsHandler(rec))
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func queryInt(r *http.Request, name string, fallback int) int {
+	v, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+func progressHandler(rec *status.Recorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, rec.Progress())
+	}
+}
+
+// errorsHandler serves a page of recorded error paths: ?offset=&limit=.
+func errorsHandler(rec *status.Recorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		offset := queryInt(r, "offset", 0)
+		limit := queryInt(r, "limit", defaultPageLimit)
+		writeJSON(w, rec.Errors(offset, limit))
+	}
+}
+
+// matchesHandler serves the most recent matches: ?limit=.
+func matchesHandler(rec *status.Recorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := queryInt(r, "limit", defaultPageLimit)
+		writeJSON(w, rec.Matches(limit))
+	}
+}
+
+// eventsHandler streams new matches/errors as server-sent events, so a
+// dashboard can tail a run live instead of polling /rest/matches.
+func eventsHandler(rec *status.Recorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush() // send headers immediately so the client's request doesn't block waiting for the first event
+
+		events, cancel := rec.Subscribe()
+		defer cancel()
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(e)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
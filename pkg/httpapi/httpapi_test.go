@@ -0,0 +1,105 @@
+package httpapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ian-Costa18/GoFind/pkg/search"
+	"github.com/Ian-Costa18/GoFind/pkg/status"
+)
+
+func TestProgressHandler(t *testing.T) {
+	rec := status.NewRecorder(&search.NumFiles{FoundFiles: 7}, 10)
+	srv := httptest.NewServer(NewServeMux(rec))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/rest/progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var p status.Progress
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.FoundFiles != 7 {
+		t.Fatalf("FoundFiles = %d, want 7", p.FoundFiles)
+	}
+}
+
+func TestMatchesAndErrorsHandlers(t *testing.T) {
+	rec := status.NewRecorder(&search.NumFiles{}, 10)
+	rec.RecordMatch(&search.FoundFile{FilePath: "a.txt"})
+	rec.RecordError("b.txt")
+	srv := httptest.NewServer(NewServeMux(rec))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/rest/matches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var matches []*search.FoundFile
+	if err := json.NewDecoder(resp.Body).Decode(&matches); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(matches) != 1 || matches[0].FilePath != "a.txt" {
+		t.Fatalf("matches = %v, want [a.txt]", matches)
+	}
+
+	resp, err = http.Get(srv.URL + "/rest/errors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs []string
+	if err := json.NewDecoder(resp.Body).Decode(&errs); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(errs) != 1 || errs[0] != "b.txt" {
+		t.Fatalf("errors = %v, want [b.txt]", errs)
+	}
+}
+
+func TestEventsHandlerStreamsLiveMatch(t *testing.T) {
+	rec := status.NewRecorder(&search.NumFiles{}, 10)
+	srv := httptest.NewServer(NewServeMux(rec))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/rest/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		rec.RecordMatch(&search.FoundFile{FilePath: "live.txt"})
+	}()
+
+	reader := bufio.NewReader(resp.Body)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var e status.Event
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Type == "match" && e.Match.FilePath == "live.txt" {
+			return
+		}
+	}
+	t.Fatal("never saw the live match event")
+}
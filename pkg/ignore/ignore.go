@@ -0,0 +1,86 @@
+// Package ignore applies gitignore-style pattern matching to the paths
+// GoFind walks, replacing a naive case-insensitive substring check that
+// matched "node_modules" inside "snode_modulesx" and couldn't express
+// things like "*.min.js" or "/build/".
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Matcher holds the fixed set of patterns read from ignore.txt and
+// ignore-types.txt, anchored at the directory a search starts from.
+// .gitignore files discovered while walking are layered on top of a
+// Matcher via Scope, not stored here, since they only apply below the
+// directory they were found in.
+type Matcher struct {
+	root string
+	base []*pattern
+}
+
+// NewMatcher returns a Matcher with no patterns yet; call AddPatterns to
+// seed it before walking root.
+func NewMatcher(root string) *Matcher {
+	return &Matcher{root: root}
+}
+
+// AddPatterns compiles lines as gitignore rules anchored at the matcher's
+// root, as read from ignore.txt/ignore-types.txt.
+func (m *Matcher) AddPatterns(lines []string) {
+	m.base = append(m.base, parse(".", lines)...)
+}
+
+// Scope is a Matcher plus the .gitignore layers accumulated while
+// descending into subdirectories. It is immutable, so it can be handed to
+// as many concurrent subdirectory walkers as fan out from it without any
+// synchronization.
+type Scope struct {
+	m      *Matcher
+	layers [][]*pattern
+}
+
+// Root returns the starting Scope for a walk of m's root directory.
+func (m *Matcher) Root() Scope {
+	return Scope{m: m}
+}
+
+// Descend returns the Scope to use while reading the directory relDir
+// (root-relative, "." for the root itself), loading relDir/.gitignore if
+// one is present so nested repositories are respected on their own terms.
+func (s Scope) Descend(relDir string) Scope {
+	data, err := os.ReadFile(filepath.Join(s.m.root, relDir, ".gitignore"))
+	if err != nil {
+		return s
+	}
+	layer := parse(relDir, strings.Split(string(data), "\n"))
+	if len(layer) == 0 {
+		return s
+	}
+	next := make([][]*pattern, len(s.layers)+1)
+	copy(next, s.layers)
+	next[len(s.layers)] = layer
+	return Scope{m: s.m, layers: next}
+}
+
+// Match reports whether relPath (root-relative) should be skipped. Patterns
+// are applied in order - base patterns first, then each .gitignore layer
+// shallowest to deepest - and the last one that matches wins, so a later,
+// more specific "!" can negate an earlier exclusion, same as git.
+func (s Scope) Match(relPath string, isDir bool) bool {
+	relPath = filepath.ToSlash(relPath)
+	ignored := false
+	apply := func(pats []*pattern) {
+		for _, p := range pats {
+			if p.match(relPath, isDir) {
+				ignored = !p.negate
+			}
+		}
+	}
+	apply(s.m.base)
+	for _, layer := range s.layers {
+		apply(layer)
+	}
+	return ignored
+}
@@ -0,0 +1,85 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatcherBasePatterns(t *testing.T) {
+	m := NewMatcher(t.TempDir())
+	m.AddPatterns([]string{"*.min.js", "/build/", "node_modules"})
+	scope := m.Root()
+
+	cases := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.min.js", false, true},
+		{"app.js", false, false},
+		{"build", true, true},
+		{"sub/build", true, false}, // anchored to the root, not below it
+		{"node_modules", true, true},
+		{"a/node_modules", true, true},   // unanchored, matches at any depth
+		{"snode_modulesx", false, false}, // no more naive substring match
+		{"a/snode_modulesx", false, false},
+	}
+	for _, c := range cases {
+		if got := scope.Match(c.path, c.isDir); got != c.want {
+			t.Errorf("Match(%q, isDir=%v) = %v, want %v", c.path, c.isDir, got, c.want)
+		}
+	}
+}
+
+func TestMatcherNegation(t *testing.T) {
+	m := NewMatcher(t.TempDir())
+	m.AddPatterns([]string{"*.log", "!keep.log"})
+	scope := m.Root()
+
+	if !scope.Match("debug.log", false) {
+		t.Fatal("debug.log should be ignored")
+	}
+	if scope.Match("keep.log", false) {
+		t.Fatal("keep.log should be negated back in")
+	}
+}
+
+func TestMatcherDoubleStar(t *testing.T) {
+	m := NewMatcher(t.TempDir())
+	m.AddPatterns([]string{"a/**/b"})
+	scope := m.Root()
+
+	for _, p := range []string{"a/b", "a/x/b", "a/x/y/b"} {
+		if !scope.Match(p, false) {
+			t.Errorf("Match(%q) = false, want true", p)
+		}
+	}
+	if scope.Match("a/c", false) {
+		t.Fatal("a/c should not match a/**/b")
+	}
+}
+
+func TestScopeDescendLoadsNestedGitignore(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "vendor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "vendor", ".gitignore"), []byte("*.cache\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMatcher(root)
+	scope := m.Root().Descend(".")
+	if scope.Match("vendor/build.cache", false) {
+		t.Fatal("nested .gitignore shouldn't apply before Descend into vendor")
+	}
+
+	vendorScope := scope.Descend("vendor")
+	if !vendorScope.Match("vendor/build.cache", false) {
+		t.Fatal("vendor/.gitignore should ignore *.cache under vendor")
+	}
+	if vendorScope.Match("other/build.cache", false) {
+		t.Fatal("vendor/.gitignore shouldn't apply outside vendor")
+	}
+}
@@ -0,0 +1,106 @@
+package ignore
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// pattern is a single compiled gitignore rule.
+type pattern struct {
+	raw     string
+	negate  bool // leading "!"
+	dirOnly bool // trailing "/"
+	base    string
+	segs    []string // path segments to match, relative to base; "**" crosses segments
+}
+
+// parse compiles lines (as read from ignore.txt/ignore-types.txt or a
+// .gitignore file) into patterns rooted at base, which is "." for the scan
+// root or a root-relative directory for a .gitignore found while walking.
+// Blank lines and "#" comments are skipped, matching git's own format.
+func parse(base string, lines []string) []*pattern {
+	var out []*pattern
+	for _, raw := range lines {
+		line := strings.TrimRight(raw, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := &pattern{raw: raw, base: base}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if line != "/" && strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		anchored := strings.HasPrefix(line, "/") || strings.Contains(strings.TrimPrefix(line, "/"), "/")
+		line = strings.TrimPrefix(line, "/")
+
+		segs := strings.Split(line, "/")
+		if !anchored {
+			// A pattern with no slash (other than a trailing one, already
+			// stripped above) can match at any depth below base, same as
+			// if it had been written "**/pattern".
+			segs = append([]string{"**"}, segs...)
+		}
+		p.segs = segs
+		out = append(out, p)
+	}
+	return out
+}
+
+// match reports whether relPath (slash-separated, relative to the scan
+// root) is covered by p.
+func (p *pattern) match(relPath string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	rel := relPath
+	if p.base != "." {
+		prefix := p.base + "/"
+		switch {
+		case rel == p.base:
+			rel = ""
+		case strings.HasPrefix(rel, prefix):
+			rel = rel[len(prefix):]
+		default:
+			return false // outside the directory this pattern was declared in
+		}
+	}
+	if rel == "" {
+		return false
+	}
+	return matchSegments(p.segs, strings.Split(rel, "/"))
+}
+
+// matchSegments matches a gitignore pattern, split into path segments,
+// against a path, also split into segments. "**" consumes zero or more
+// path segments; any other segment is matched with filepath.Match so
+// "*", "?" and "[...]" behave as usual within a single segment.
+func matchSegments(pat, path []string) bool {
+	if len(pat) == 0 {
+		return len(path) == 0
+	}
+	if pat[0] == "**" {
+		if matchSegments(pat[1:], path) {
+			return true
+		}
+		if len(path) == 0 {
+			return false
+		}
+		return matchSegments(pat, path[1:])
+	}
+	if len(path) == 0 {
+		return false
+	}
+	ok, err := filepath.Match(pat[0], path[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pat[1:], path[1:])
+}
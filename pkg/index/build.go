@@ -0,0 +1,176 @@
+package index
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Ian-Costa18/GoFind/pkg/content"
+	"github.com/Ian-Costa18/GoFind/pkg/ignore"
+)
+
+const (
+	// maxIndexSize is the largest file we will extract trigrams from.
+	// Bigger files are still recorded in the file list (so the index stays
+	// a complete picture of the tree) but excluded from the postings.
+	maxIndexSize = 32 << 20
+)
+
+// candidate is a file found while walking the tree, before we've decided
+// whether it can be reused from a previous index.
+type candidate struct {
+	path string
+	info os.FileInfo
+}
+
+// Build walks directory and produces a fresh Index, reusing trigram data
+// from prev for any file whose path, size and mtime are unchanged so a
+// rebuild doesn't have to reread and re-tokenize the whole tree. prev may
+// be nil for a from-scratch build. sniffer decides which files are binary
+// and decodes known container formats (gzip, Office, PDF) to text before
+// they're tokenized, so the index's view of "is this file searchable"
+// matches the same Sniffer a Searcher runs against it - otherwise a file
+// sniffer can decode (and search can find matches in) would silently never
+// make it into the candidate set an index produces.
+func Build(directory string, ignored []string, prev *Index, sniffer *content.Sniffer) (*Index, error) {
+	matcher := ignore.NewMatcher(directory)
+	matcher.AddPatterns(ignored)
+	// scopes caches the .gitignore-layered Scope for each directory
+	// already visited, keyed by its path relative to directory. WalkDir
+	// visits a directory before any of its entries, so a child's scope is
+	// always looked up after its parent's has been computed and stored.
+	scopes := map[string]ignore.Scope{".": matcher.Root().Descend(".")}
+
+	var candidates []candidate
+	walkFunc := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(directory, path)
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if rel == "." {
+				return nil
+			}
+			parent := scopes[filepath.Dir(rel)]
+			scope := parent.Descend(rel)
+			if scope.Match(rel, true) {
+				return filepath.SkipDir
+			}
+			scopes[rel] = scope
+			return nil
+		}
+		if scopes[filepath.Dir(rel)].Match(rel, false) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		candidates = append(candidates, candidate{path: path, info: info})
+		return nil
+	}
+	if err := filepath.WalkDir(directory, walkFunc); err != nil {
+		return nil, err
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].path < candidates[j].path })
+
+	prevByPath := make(map[string]int32, len(prev.filesOrEmpty()))
+	for id, f := range prev.filesOrEmpty() {
+		prevByPath[f.Path] = int32(id)
+	}
+	prevTrigrams := prev.invertPostings()
+
+	idx := New()
+	for _, c := range candidates {
+		meta := FileMeta{Path: c.path, ModTime: c.info.ModTime(), Size: c.info.Size()}
+		newID := int32(len(idx.Files))
+
+		var trigrams []string
+		if oldID, ok := prevByPath[c.path]; ok {
+			old := prev.Files[oldID]
+			if old.ModTime.Equal(meta.ModTime) && old.Size == meta.Size {
+				meta.Indexed = old.Indexed
+				trigrams = prevTrigrams[oldID]
+			} else {
+				meta.Indexed, trigrams = extract(c.path, meta.Size, sniffer)
+			}
+		} else {
+			meta.Indexed, trigrams = extract(c.path, meta.Size, sniffer)
+		}
+
+		idx.Files = append(idx.Files, meta)
+		for _, g := range trigrams {
+			idx.Postings[g] = append(idx.Postings[g], newID)
+		}
+	}
+
+	for g, ids := range idx.Postings {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		idx.Postings[g] = ids
+	}
+	return idx, nil
+}
+
+// extract returns whether a file was indexed and, if so, the distinct
+// trigrams it contains. It reads the file through sniffer rather than
+// directly, so a .gz/.docx/.xlsx/.pptx/.pdf is tokenized from its decoded
+// text (exactly what a Searcher using the same sniffer would scan) and
+// "binary" means whatever sniffer.Binary says it means, instead of an
+// independent NUL-byte check over raw bytes. Files over maxIndexSize are
+// excluded outright.
+func extract(path string, size int64, sniffer *content.Sniffer) (bool, []string) {
+	if size > maxIndexSize {
+		return false, nil
+	}
+	r, scan, err := sniffer.Open(path)
+	if err != nil || !scan {
+		return false, nil
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return false, nil
+	}
+	return true, trigrams(data)
+}
+
+// trigrams returns the set of distinct 3-byte sequences present in data.
+func trigrams(data []byte) []string {
+	seen := make(map[string]bool)
+	for i := 0; i+3 <= len(data); i++ {
+		seen[string(data[i:i+3])] = true
+	}
+	out := make([]string, 0, len(seen))
+	for g := range seen {
+		out = append(out, g)
+	}
+	return out
+}
+
+func (idx *Index) filesOrEmpty() []FileMeta {
+	if idx == nil {
+		return nil
+	}
+	return idx.Files
+}
+
+// invertPostings reconstructs, for each file ID, the trigrams that file
+// contributed, by scanning the postings map. Kept out of the on-disk
+// format so the index only stores the inverted (trigram -> files) form.
+func (idx *Index) invertPostings() map[int32][]string {
+	out := make(map[int32][]string)
+	if idx == nil {
+		return out
+	}
+	for g, ids := range idx.Postings {
+		for _, id := range ids {
+			out[id] = append(out[id], g)
+		}
+	}
+	return out
+}
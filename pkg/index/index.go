@@ -0,0 +1,27 @@
+// Package index implements a Google-codesearch-style trigram index over a
+// directory tree, so repeated GoFind searches don't need to rescan every
+// line of every file.
+package index
+
+import "time"
+
+// FileMeta records enough about a file to detect whether it has changed
+// since the last index build, plus whether it was actually indexed.
+type FileMeta struct {
+	Path    string
+	ModTime time.Time
+	Size    int64
+	Indexed bool // false when skipped for size/binary reasons
+}
+
+// Index is the in-memory form of the trigram postings file: a sorted file
+// list and a map of trigram -> sorted file IDs (positions into Files).
+type Index struct {
+	Files    []FileMeta
+	Postings map[string][]int32
+}
+
+// New returns an empty Index ready to be populated by Build.
+func New() *Index {
+	return &Index{Postings: make(map[string][]int32)}
+}
@@ -0,0 +1,150 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/Ian-Costa18/GoFind/pkg/content"
+)
+
+func writeFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBuildAndQueryLiteral(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.txt", "hello trigram world")
+	writeFile(t, dir, "b.txt", "nothing interesting here")
+
+	idx, err := Build(dir, nil, nil, content.NewSniffer(content.Skip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := idx.QueryLiteral("trigram")
+	if len(got) != 1 || filepath.Base(got[0]) != "a.txt" {
+		t.Fatalf("QueryLiteral(trigram) = %v, want just a.txt", got)
+	}
+	if got := idx.QueryLiteral("xyzzyxyzzy"); got != nil {
+		t.Fatalf("QueryLiteral(xyzzyxyzzy) = %v, want nil", got)
+	}
+}
+
+func TestBuildSkipsBinary(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "bin.dat", "abc\x00def")
+	writeFile(t, dir, "text.txt", "plain text file")
+
+	idx, err := Build(dir, nil, nil, content.NewSniffer(content.Skip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sawBinary, sawText bool
+	for _, f := range idx.Files {
+		switch filepath.Base(f.Path) {
+		case "bin.dat":
+			sawBinary = true
+			if f.Indexed {
+				t.Errorf("bin.dat should not be indexed")
+			}
+		case "text.txt":
+			sawText = true
+			if !f.Indexed {
+				t.Errorf("text.txt should be indexed")
+			}
+		}
+	}
+	if !sawBinary || !sawText {
+		t.Fatalf("expected both files recorded, got %+v", idx.Files)
+	}
+}
+
+func TestBuildReusesUnchangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.txt", "some needle content")
+
+	first, err := Build(dir, nil, nil, content.NewSniffer(content.Skip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Build(dir, nil, first, content.NewSniffer(content.Skip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := second.QueryLiteral("needle"); len(got) != 1 {
+		t.Fatalf("QueryLiteral(needle) after rebuild = %v, want 1 match", got)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.txt", "roundtrip content")
+
+	idx, err := Build(dir, nil, nil, content.NewSniffer(content.Skip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idxPath := filepath.Join(dir, "index.idx")
+	if err := Save(idx, idxPath); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(idxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Files) != len(idx.Files) {
+		t.Fatalf("loaded %d files, want %d", len(loaded.Files), len(idx.Files))
+	}
+	if got := loaded.QueryLiteral("roundtrip"); len(got) != 1 {
+		t.Fatalf("QueryLiteral(roundtrip) after load = %v, want 1 match", got)
+	}
+}
+
+func TestQueryRegex(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.txt", "error: connection refused")
+	writeFile(t, dir, "b.txt", "all good here")
+
+	idx, err := Build(dir, nil, nil, content.NewSniffer(content.Skip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(`error: \w+`)
+	got := idx.QueryRegex(re)
+	sort.Strings(got)
+	if len(got) != 1 || filepath.Base(got[0]) != "a.txt" {
+		t.Fatalf("QueryRegex(error: ) = %v, want just a.txt", got)
+	}
+}
+
+// TestQueryRegexNonAdjacentLiterals covers a regex whose literal pieces
+// are required but not adjacent (a "." or similar gap between them). A
+// prior version of requiredTrigrams crossed "foo" and "bar" into the
+// single combined requirement "foobar", which no match of "foo.bar" is
+// guaranteed to contain, so the index silently dropped real matches.
+func TestQueryRegexNonAdjacentLiterals(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.txt", "foo\nbar")
+	writeFile(t, dir, "b.txt", "neither literal here")
+
+	idx, err := Build(dir, nil, nil, content.NewSniffer(content.Skip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(`(?s)foo.bar`)
+	if !re.MatchString("foo\nbar") {
+		t.Fatal("test regex should match the fixture content")
+	}
+	got := idx.QueryRegex(re)
+	sort.Strings(got)
+	if len(got) != 1 || filepath.Base(got[0]) != "a.txt" {
+		t.Fatalf("QueryRegex((?s)foo.bar) = %v, want just a.txt", got)
+	}
+}
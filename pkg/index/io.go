@@ -0,0 +1,182 @@
+package index
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+func unixNano(nano int64) time.Time {
+	return time.Unix(0, nano).UTC()
+}
+
+// magic identifies a GoFind trigram index file, followed by a format
+// version byte so the encoding can change later without guessing.
+var magic = [4]byte{'G', 'F', 'I', 'X'}
+
+const formatVersion = 1
+
+// Save writes idx to path. File metadata is stored verbatim; postings are
+// delta-encoded (IDs within a trigram's list are strictly increasing, so
+// storing the gaps keeps the varints small) and varint-packed.
+func Save(idx *Index, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+
+	if _, err := w.Write(magic[:]); err != nil {
+		return err
+	}
+	if err := w.WriteByte(formatVersion); err != nil {
+		return err
+	}
+
+	var buf [binary.MaxVarintLen64]byte
+	writeUvarint := func(v uint64) error {
+		n := binary.PutUvarint(buf[:], v)
+		_, err := w.Write(buf[:n])
+		return err
+	}
+
+	if err := writeUvarint(uint64(len(idx.Files))); err != nil {
+		return err
+	}
+	for _, file := range idx.Files {
+		if err := writeUvarint(uint64(len(file.Path))); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(file.Path); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, file.ModTime.UnixNano()); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, file.Size); err != nil {
+			return err
+		}
+		indexed := byte(0)
+		if file.Indexed {
+			indexed = 1
+		}
+		if err := w.WriteByte(indexed); err != nil {
+			return err
+		}
+	}
+
+	if err := writeUvarint(uint64(len(idx.Postings))); err != nil {
+		return err
+	}
+	for trigram, ids := range idx.Postings {
+		if len(trigram) != 3 {
+			return fmt.Errorf("index: trigram %q is not 3 bytes", trigram)
+		}
+		if _, err := w.WriteString(trigram); err != nil {
+			return err
+		}
+		if err := writeUvarint(uint64(len(ids))); err != nil {
+			return err
+		}
+		var prev int32
+		for _, id := range ids {
+			if err := writeUvarint(uint64(id - prev)); err != nil {
+				return err
+			}
+			prev = id
+		}
+	}
+
+	return w.Flush()
+}
+
+// Load reads an Index previously written by Save.
+func Load(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	var got [4]byte
+	if _, err := io.ReadFull(r, got[:]); err != nil {
+		return nil, err
+	}
+	if got != magic {
+		return nil, fmt.Errorf("index: %s is not a GoFind index file", path)
+	}
+	version, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if version != formatVersion {
+		return nil, fmt.Errorf("index: unsupported index format version %d", version)
+	}
+
+	numFiles, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	idx := New()
+	idx.Files = make([]FileMeta, numFiles)
+	for i := range idx.Files {
+		pathLen, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		pathBytes := make([]byte, pathLen)
+		if _, err := io.ReadFull(r, pathBytes); err != nil {
+			return nil, err
+		}
+		var modNano, size int64
+		if err := binary.Read(r, binary.LittleEndian, &modNano); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, err
+		}
+		indexed, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		idx.Files[i] = FileMeta{
+			Path:    string(pathBytes),
+			ModTime: unixNano(modNano),
+			Size:    size,
+			Indexed: indexed == 1,
+		}
+	}
+
+	numPostings, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < numPostings; i++ {
+		trigram := make([]byte, 3)
+		if _, err := io.ReadFull(r, trigram); err != nil {
+			return nil, err
+		}
+		count, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]int32, count)
+		var prev int32
+		for j := range ids {
+			delta, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			prev += int32(delta)
+			ids[j] = prev
+		}
+		idx.Postings[string(trigram)] = ids
+	}
+
+	return idx, nil
+}
@@ -0,0 +1,391 @@
+package index
+
+import (
+	"regexp"
+	"regexp/syntax"
+)
+
+// PathsFor returns the on-disk paths for a slice of file IDs.
+func (idx *Index) PathsFor(ids []int32) []string {
+	paths := make([]string, 0, len(ids))
+	for _, id := range ids {
+		paths = append(paths, idx.Files[id].Path)
+	}
+	return paths
+}
+
+// AllIndexedPaths returns every file the index knows was tokenized. It's
+// the fallback candidate set when a query can't be narrowed by trigrams.
+func (idx *Index) AllIndexedPaths() []string {
+	paths := make([]string, 0, len(idx.Files))
+	for _, f := range idx.Files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+// QueryLiteral returns the candidate files that could contain literal,
+// computed as the intersection of the postings for each trigram in it.
+// Literals shorter than 3 bytes can't be narrowed, so every indexed file
+// is returned.
+func (idx *Index) QueryLiteral(literal string) []string {
+	if len(literal) < 3 {
+		return idx.AllIndexedPaths()
+	}
+	var ids []int32
+	have := false
+	for i := 0; i+3 <= len(literal); i++ {
+		g := literal[i : i+3]
+		postings, ok := idx.Postings[g]
+		if !ok {
+			return nil
+		}
+		if !have {
+			ids = postings
+			have = true
+			continue
+		}
+		ids = intersect(ids, postings)
+		if len(ids) == 0 {
+			return nil
+		}
+	}
+	return idx.PathsFor(ids)
+}
+
+// QueryRegex returns the candidate files that could match re, derived by
+// turning the regex into a required trigram expression (an AND/OR of
+// literal 3-byte sequences pulled from the regex's prefix/exact/suffix
+// literals) and evaluating it against the postings.
+func (idx *Index) QueryRegex(re *regexp.Regexp) []string {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return idx.AllIndexedPaths()
+	}
+	q := requiredTrigrams(parsed)
+	ids := q.eval(idx)
+	if ids == nil {
+		return idx.AllIndexedPaths()
+	}
+	return idx.PathsFor(ids)
+}
+
+func intersect(a, b []int32) []int32 {
+	out := make([]int32, 0, min(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return out
+}
+
+func union(a, b []int32) []int32 {
+	out := make([]int32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		default:
+			out = append(out, b[j])
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	out = append(out, b[j:]...)
+	return out
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// query is a boolean expression over trigrams, sum-of-products style: an
+// "and" node requires postings for every child, an "or" requires postings
+// for at least one. A nil query means "no usable trigram information" and
+// callers should fall back to scanning every indexed file.
+type query struct {
+	op       queryOp
+	trigram  string // set when op == qTrigram
+	children []*query
+}
+
+type queryOp int
+
+const (
+	qTrigram queryOp = iota
+	qAnd
+	qOr
+)
+
+func trigramQuery(g string) *query { return &query{op: qTrigram, trigram: g} }
+
+func andQuery(parts ...*query) *query {
+	parts = compact(parts)
+	switch len(parts) {
+	case 0:
+		return nil
+	case 1:
+		return parts[0]
+	default:
+		return &query{op: qAnd, children: parts}
+	}
+}
+
+// orQuery requires at least one branch to match. If any branch couldn't be
+// reduced to trigram information, the whole OR is unresolved, since a match
+// could have taken that branch and skipped the trigrams we do know about.
+func orQuery(parts ...*query) *query {
+	for _, p := range parts {
+		if p == nil {
+			return nil
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return nil
+	case 1:
+		return parts[0]
+	default:
+		return &query{op: qOr, children: parts}
+	}
+}
+
+func compact(parts []*query) []*query {
+	out := parts[:0]
+	for _, p := range parts {
+		if p != nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (q *query) eval(idx *Index) []int32 {
+	if q == nil {
+		return nil
+	}
+	switch q.op {
+	case qTrigram:
+		ids, ok := idx.Postings[q.trigram]
+		if !ok {
+			return []int32{}
+		}
+		return ids
+	case qAnd:
+		var ids []int32
+		for i, c := range q.children {
+			sub := c.eval(idx)
+			if i == 0 {
+				ids = sub
+			} else {
+				ids = intersect(ids, sub)
+			}
+		}
+		return ids
+	case qOr:
+		var ids []int32
+		for i, c := range q.children {
+			sub := c.eval(idx)
+			if sub == nil {
+				return nil
+			}
+			if i == 0 {
+				ids = sub
+			} else {
+				ids = union(ids, sub)
+			}
+		}
+		return ids
+	}
+	return nil
+}
+
+// requiredTrigrams walks a parsed regex and builds a required trigram
+// expression from any literal text it's guaranteed to contain, mirroring
+// the approach codesearch tools use to prune candidates before running
+// the real regex. Constructs that can't be reduced to literal text (star,
+// char classes, ".", etc.) contribute no information.
+//
+// Concatenation needs care: literal subexpressions that are actually
+// adjacent in the regex (e.g. "foo" followed by "bar") can be crossed
+// into the single combined requirement "foobar", but a non-literal gap
+// between two literal subs (e.g. "foo" + "." + "bar") must NOT be
+// bridged that way - "foo.bar" only requires "foo" and "bar" to each
+// appear somewhere, with one arbitrary byte between them, not the exact
+// substring "foobar". concatQuery below closes out a literal run (and
+// ANDs it with the rest) the moment a gap is hit, instead of crossing
+// across it.
+func requiredTrigrams(re *syntax.Regexp) *query {
+	switch re.Op {
+	case syntax.OpCapture, syntax.OpPlus:
+		// A capture group contributes whatever its body requires; x+
+		// always contains at least one copy of whatever x requires.
+		return requiredTrigrams(re.Sub[0])
+	case syntax.OpConcat:
+		return concatQuery(re.Sub)
+	case syntax.OpAlternate:
+		var parts []*query
+		for _, sub := range re.Sub {
+			p := requiredTrigrams(sub)
+			if p == nil {
+				return nil
+			}
+			parts = append(parts, p)
+		}
+		return orQuery(parts...)
+	default:
+		lits, _ := literals(re)
+		return orLiteralSet(lits)
+	}
+}
+
+// orLiteralSet requires at least one of lits' trigram sets: a match is
+// guaranteed to contain one of these alternative literal strings.
+func orLiteralSet(lits []string) *query {
+	if len(lits) == 0 {
+		return nil
+	}
+	var parts []*query
+	for _, lit := range lits {
+		parts = append(parts, literalTrigrams(lit))
+	}
+	return orQuery(parts...)
+}
+
+// concatQuery builds the AND of every independent literal requirement in
+// a sequence of concatenated subexpressions. Subs that reduce to a flat
+// literal-alternative set (via literals) are crossed together within a
+// contiguous run, since they sit directly adjacent to one another in the
+// regex; a sub that isn't flatly literal (".", a char class, or a nested
+// group with its own internal gap) closes out the current run and is
+// queried independently via requiredTrigrams instead of being bridged
+// into it.
+func concatQuery(subs []*syntax.Regexp) *query {
+	var ands []*query
+	run := []string{""}
+	runActive := false
+	flush := func() {
+		if runActive {
+			if q := orLiteralSet(run); q != nil {
+				ands = append(ands, q)
+			}
+		}
+		run = []string{""}
+		runActive = false
+	}
+	for _, sub := range subs {
+		if lits, _ := literals(sub); len(lits) > 0 {
+			run = cross(run, lits)
+			runActive = true
+			if len(run) > maxLiteralSet {
+				flush()
+			}
+			continue
+		}
+		flush()
+		if q := requiredTrigrams(sub); q != nil {
+			ands = append(ands, q)
+		}
+	}
+	flush()
+	return andQuery(ands...)
+}
+
+// literalTrigrams requires ALL trigrams of a literal string (AND), since
+// a match containing the literal contains every trigram within it.
+func literalTrigrams(lit string) *query {
+	if len(lit) < 3 {
+		return nil
+	}
+	var parts []*query
+	for i := 0; i+3 <= len(lit); i++ {
+		parts = append(parts, trigramQuery(lit[i:i+3]))
+	}
+	return andQuery(parts...)
+}
+
+const maxLiteralSet = 16
+
+// literals returns a bounded set of literal strings that re is guaranteed
+// to contain (as substrings of any match), and whether that set captures
+// every possible match exactly. Unbounded or non-literal constructs
+// collapse the result to (nil, false).
+func literals(re *syntax.Regexp) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}, true
+	case syntax.OpCapture:
+		return literals(re.Sub[0])
+	case syntax.OpConcat:
+		// literals reduces a node to a single flat set of literal
+		// alternatives, which only makes sense if every sub in the
+		// concatenation is itself literal - a non-literal sub (a gap)
+		// means there's no single substring the whole concatenation is
+		// guaranteed to contain, so bail rather than bridge across it.
+		// concatQuery (in requiredTrigrams) is what handles that case,
+		// by requiring each side of the gap independently.
+		set := []string{""}
+		exact := true
+		for _, sub := range re.Sub {
+			subLits, subExact := literals(sub)
+			if len(subLits) == 0 {
+				return nil, false
+			}
+			exact = exact && subExact
+			set = cross(set, subLits)
+			if len(set) > maxLiteralSet {
+				return nil, false
+			}
+		}
+		return set, exact
+	case syntax.OpAlternate:
+		var set []string
+		exact := true
+		for _, sub := range re.Sub {
+			subLits, subExact := literals(sub)
+			if len(subLits) == 0 {
+				return nil, false
+			}
+			exact = exact && subExact
+			set = append(set, subLits...)
+			if len(set) > maxLiteralSet {
+				return nil, false
+			}
+		}
+		return set, exact
+	case syntax.OpPlus:
+		// x+ always contains at least one copy of whatever x requires.
+		subLits, _ := literals(re.Sub[0])
+		return subLits, false
+	default:
+		return nil, false
+	}
+}
+
+func cross(a, b []string) []string {
+	out := make([]string, 0, len(a)*len(b))
+	for _, x := range a {
+		for _, y := range b {
+			out = append(out, x+y)
+		}
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/Ian-Costa18/GoFind/pkg/search"
+)
+
+// ndjsonRecord is one line of NDJSON output: a file and every match found
+// in it.
+type ndjsonRecord struct {
+	Path    string        `json:"path"`
+	Matches []ndjsonMatch `json:"matches"`
+}
+
+type ndjsonMatch struct {
+	Rule     string `json:"rule"`
+	RuleType string `json:"ruleType"`
+	Line     int    `json:"line"`
+	Col      int    `json:"col"`
+	Snippet  string `json:"snippet"`
+}
+
+type ndjsonWriter struct {
+	w io.Writer
+}
+
+func (n *ndjsonWriter) Write(f *search.FoundFile) error {
+	rec := ndjsonRecord{Path: f.FilePath, Matches: make([]ndjsonMatch, len(f.Matches))}
+	for i, m := range f.Matches {
+		rec.Matches[i] = ndjsonMatch{Rule: m.Rule, RuleType: m.RuleType, Line: m.Line, Col: m.Col, Snippet: m.Snippet}
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = n.w.Write(data)
+	return err
+}
+
+func (n *ndjsonWriter) Close() error { return nil }
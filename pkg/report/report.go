@@ -0,0 +1,59 @@
+// Package report renders GoFind's search results as text, NDJSON, or
+// SARIF, replacing the old "Path: ... | Keywords: kw::line & kw2::line"
+// line, which was unparseable by downstream tools and ambiguous whenever a
+// keyword itself contained " & ".
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Ian-Costa18/GoFind/pkg/search"
+)
+
+// Format selects how a Writer renders FoundFiles.
+type Format string
+
+const (
+	Text   Format = "text"
+	NDJSON Format = "ndjson"
+	SARIF  Format = "sarif"
+)
+
+// Writer renders FoundFiles as they're found. Text and NDJSON write (and,
+// for NDJSON, the underlying os.File write is itself unbuffered, so the
+// stream can be tailed) as each FoundFile arrives; SARIF must buffer every
+// match until Close, since a SARIF report is a single JSON document.
+type Writer interface {
+	Write(f *search.FoundFile) error
+	Close() error
+}
+
+// NewWriter returns a Writer for format, writing to w. An empty format
+// defaults to Text.
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case "", Text:
+		return &textWriter{w: w}, nil
+	case NDJSON:
+		return &ndjsonWriter{w: w}, nil
+	case SARIF:
+		return &sarifWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("report: unknown format %q", format)
+	}
+}
+
+type textWriter struct{ w io.Writer }
+
+func (t *textWriter) Write(f *search.FoundFile) error {
+	rules := make([]string, 0, len(f.Matches))
+	for _, m := range f.Matches {
+		rules = append(rules, fmt.Sprintf("%s::%d", m.Rule, m.Line))
+	}
+	_, err := fmt.Fprintf(t.w, "Path: %s | Matches: %s\n", f.FilePath, strings.Join(rules, " & "))
+	return err
+}
+
+func (t *textWriter) Close() error { return nil }
@@ -0,0 +1,96 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Ian-Costa18/GoFind/pkg/search"
+)
+
+func sampleFile() *search.FoundFile {
+	return &search.FoundFile{
+		FilePath: "a.txt",
+		Matches: []search.Match{
+			{Rule: "needle", RuleType: search.RuleKeyword, Line: 3, Col: 5, Snippet: "found needle here"},
+		},
+	}
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	if _, err := NewWriter("xml", &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestTextWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(Text, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(sampleFile()); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.Contains(got, "a.txt") || !strings.Contains(got, "needle::3") {
+		t.Fatalf("text output = %q, want it to mention the path and needle::3", got)
+	}
+}
+
+func TestNDJSONWriterOneObjectPerLine(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(NDJSON, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(sampleFile()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(sampleFile()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var rec ndjsonRecord
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("line 1 is not valid JSON: %v", err)
+	}
+	if rec.Path != "a.txt" || len(rec.Matches) != 1 || rec.Matches[0].Rule != "needle" {
+		t.Fatalf("decoded record = %+v, want path a.txt with a needle match", rec)
+	}
+}
+
+func TestSARIFWriterBuffersUntilClose(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(SARIF, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(sampleFile()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatal("SARIF writer should not write anything before Close")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc sarifDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Close did not produce valid JSON: %v", err)
+	}
+	if doc.Version != "2.1.0" || len(doc.Runs) != 1 {
+		t.Fatalf("doc = %+v, want one run at version 2.1.0", doc)
+	}
+	run := doc.Runs[0]
+	if len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ID != "kw:needle" {
+		t.Fatalf("rules = %+v, want a single kw:needle rule", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 1 || run.Results[0].Locations[0].PhysicalLocation.Region.StartLine != 3 {
+		t.Fatalf("results = %+v, want one result at line 3", run.Results)
+	}
+}
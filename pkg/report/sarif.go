@@ -0,0 +1,127 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Ian-Costa18/GoFind/pkg/search"
+)
+
+// sarifWriter buffers every FoundFile and renders them as a single SARIF
+// 2.1.0 report on Close, one "rule" per distinct keyword/regex and one
+// "result" per match - this is what makes GoFind's output consumable by
+// GitHub code scanning and similar dashboards.
+type sarifWriter struct {
+	w     io.Writer
+	files []*search.FoundFile
+}
+
+func (s *sarifWriter) Write(f *search.FoundFile) error {
+	s.files = append(s.files, f)
+	return nil
+}
+
+func (s *sarifWriter) Close() error {
+	enc := json.NewEncoder(s.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(buildSARIF(s.files))
+}
+
+type sarifDocument struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine"`
+	StartColumn int           `json:"startColumn"`
+	Snippet     *sarifSnippet `json:"snippet,omitempty"`
+}
+
+type sarifSnippet struct {
+	Text string `json:"text"`
+}
+
+// buildSARIF assembles a run's worth of rules and results from files,
+// de-duplicating rules by their ruleType:rule identity in first-seen
+// order.
+func buildSARIF(files []*search.FoundFile) sarifDocument {
+	var rules []sarifRule
+	seen := make(map[string]bool)
+	var results []sarifResult
+	for _, f := range files {
+		for _, m := range f.Matches {
+			id := m.RuleType + ":" + m.Rule
+			if !seen[id] {
+				seen[id] = true
+				rules = append(rules, sarifRule{ID: id, Name: m.Rule})
+			}
+			results = append(results, sarifResult{
+				RuleID:  id,
+				Message: sarifMessage{Text: fmt.Sprintf("%q matched", m.Rule)},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: f.FilePath},
+						Region: sarifRegion{
+							StartLine:   m.Line,
+							StartColumn: m.Col,
+							Snippet:     &sarifSnippet{Text: m.Snippet},
+						},
+					},
+				}},
+			})
+		}
+	}
+	return sarifDocument{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "GoFind", Rules: rules}},
+			Results: results,
+		}},
+	}
+}
@@ -0,0 +1,280 @@
+// Package search runs GoFind's file walk and keyword/regex scan through a
+// bounded pool of workers, so a large tree can't explode into one goroutine
+// per file and exhaust file descriptors.
+package search
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Ian-Costa18/GoFind/pkg/content"
+	"github.com/Ian-Costa18/GoFind/pkg/ignore"
+)
+
+// Rule types a Match can come from.
+const (
+	RuleKeyword = "kw"
+	RuleRegex   = "re"
+)
+
+// Match is a single keyword/regex hit within a file.
+type Match struct {
+	Rule     string // the keyword or regex source text that matched
+	RuleType string // RuleKeyword or RuleRegex
+	Line     int    // 1-based line number
+	Col      int    // 1-based byte offset of the match within the line
+	Snippet  string // the full line the match was found on
+}
+
+// FoundFile is the output contract for a file search hit: a path and every
+// keyword/regex match found in it.
+type FoundFile struct {
+	FilePath string
+	Matches  []Match
+}
+
+// NumFiles tracks run-wide counters, read concurrently via the atomic
+// package while a search is in progress.
+type NumFiles struct {
+	FoundFiles    uint64
+	SearchedFiles uint64
+	NumErrors     uint64
+	NumIgnored    uint64
+}
+
+// maxDirWorkers bounds how many directories can be read concurrently,
+// separately from ThreadCount, since directory reads are cheap and I/O
+// bound while file scans are what we want to cap hard.
+const maxDirWorkers = 4
+
+// Searcher owns a fixed pool of workers that consume file paths from a
+// bounded channel and scan them for keywords/regexes. Unlike spawning a
+// goroutine per file, the channel's capacity naturally throttles the
+// directory walk: once it's full, the walker blocks until a worker frees a
+// slot.
+type Searcher struct {
+	Directory   string
+	Keywords    []string
+	Regexs      []*regexp.Regexp
+	Ignore      *ignore.Matcher
+	Content     *content.Sniffer
+	ThreadCount int
+
+	// Candidates, if non-nil, is searched instead of walking Directory -
+	// this is how an index's narrowed file list gets plugged in.
+	Candidates []string
+
+	Output  chan *FoundFile
+	Errors  chan string
+	Counter *NumFiles
+}
+
+// New returns a Searcher with its output channels and counters allocated,
+// ready for Run. ignoredTypes is parsed with gitignore semantics (see
+// pkg/ignore) and applied on top of any .gitignore files the walk finds.
+// binaryMode controls how files the content sniffer can't decode to text
+// are handled (see pkg/content.BinaryMode); an empty string defaults to
+// content.Skip.
+func New(directory string, keywords []string, regexs []*regexp.Regexp, ignoredTypes []string, threadCount int, binaryMode content.BinaryMode) *Searcher {
+	if threadCount <= 0 {
+		threadCount = runtime.NumCPU()
+	}
+	matcher := ignore.NewMatcher(directory)
+	matcher.AddPatterns(ignoredTypes)
+	return &Searcher{
+		Directory:   directory,
+		Keywords:    keywords,
+		Regexs:      regexs,
+		Ignore:      matcher,
+		Content:     content.NewSniffer(binaryMode),
+		ThreadCount: threadCount,
+		Output:      make(chan *FoundFile),
+		Errors:      make(chan string),
+		Counter:     &NumFiles{},
+	}
+}
+
+// Run walks (or, if Candidates is set, replays) the file list through the
+// worker pool until every file has been searched or ctx is cancelled. On
+// cancellation - e.g. SIGINT - the walk stops feeding new paths and
+// in-flight workers finish their current file, so Output/Errors still end
+// cleanly via close rather than being abandoned mid-write.
+func (s *Searcher) Run(ctx context.Context) error {
+	paths := make(chan string, s.ThreadCount)
+
+	var workers sync.WaitGroup
+	for i := 0; i < s.ThreadCount; i++ {
+		workers.Add(1)
+		go s.searchWorker(ctx, paths, &workers)
+	}
+
+	if s.Candidates != nil {
+		go s.feedCandidates(ctx, paths)
+	} else {
+		var dirs sync.WaitGroup
+		sem := make(chan struct{}, dirWorkerCount(s.ThreadCount))
+		dirs.Add(1)
+		go s.walkDir(ctx, s.Directory, ".", s.Ignore.Root(), paths, sem, &dirs)
+		go func() {
+			dirs.Wait()
+			close(paths)
+		}()
+	}
+
+	workers.Wait()
+	close(s.Output)
+	close(s.Errors)
+	return ctx.Err()
+}
+
+func dirWorkerCount(threadCount int) int {
+	if threadCount < maxDirWorkers {
+		return threadCount
+	}
+	return maxDirWorkers
+}
+
+func (s *Searcher) feedCandidates(ctx context.Context, paths chan<- string) {
+	defer close(paths)
+	for _, path := range s.Candidates {
+		atomic.AddUint64(&s.Counter.FoundFiles, 1)
+		select {
+		case paths <- path:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// walkDir reads one directory and, for each entry, either recurses (via a
+// new goroutine, bounded by sem) or pushes the file path onto paths. Paths
+// sent here are what create backpressure: once paths is full the send
+// blocks, so walkDir naturally stalls rather than piling up goroutines.
+// relDir is dir's path relative to s.Directory ("." for the root itself),
+// and scope carries the .gitignore layers accumulated by its ancestors;
+// walkDir loads dir's own .gitignore, if any, before matching its entries.
+func (s *Searcher) walkDir(ctx context.Context, dir, relDir string, scope ignore.Scope, paths chan<- string, sem chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	<-sem
+	if err != nil {
+		s.sendError(ctx, fmt.Sprintf("%s = %v", dir, err))
+		return
+	}
+	scope = scope.Descend(relDir)
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		full := filepath.Join(dir, entry.Name())
+		rel := filepath.Join(relDir, entry.Name())
+		if scope.Match(rel, entry.IsDir()) {
+			atomic.AddUint64(&s.Counter.NumIgnored, 1)
+			continue
+		}
+		if entry.IsDir() {
+			wg.Add(1)
+			go s.walkDir(ctx, full, rel, scope, paths, sem, wg)
+			continue
+		}
+		atomic.AddUint64(&s.Counter.FoundFiles, 1)
+		select {
+		case paths <- full:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Searcher) sendError(ctx context.Context, msg string) {
+	select {
+	case s.Errors <- msg:
+	case <-ctx.Done():
+	}
+}
+
+func (s *Searcher) searchWorker(ctx context.Context, paths <-chan string, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		select {
+		case path, ok := <-paths:
+			if !ok {
+				return
+			}
+			s.searchFile(ctx, path)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Searcher) searchFile(ctx context.Context, filePath string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.sendError(ctx, fmt.Sprintf("%s = %v", filePath, r))
+		}
+	}()
+
+	r, scan, err := s.Content.Open(filePath)
+	if err != nil {
+		s.sendError(ctx, fmt.Sprintf("%s = %v", filePath, err))
+		return
+	}
+	if !scan {
+		atomic.AddUint64(&s.Counter.NumIgnored, 1)
+		return
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	file := &FoundFile{FilePath: strings.TrimSpace(filePath)}
+	line := 1
+	for scanner.Scan() {
+		lineText := scanner.Text()
+		for _, kw := range s.Keywords {
+			if col := strings.Index(lineText, kw); col >= 0 {
+				file.Matches = append(file.Matches, Match{Rule: kw, RuleType: RuleKeyword, Line: line, Col: col + 1, Snippet: lineText})
+			}
+		}
+		for _, re := range s.Regexs {
+			if re == nil {
+				continue
+			}
+			if loc := re.FindStringIndex(lineText); loc != nil {
+				file.Matches = append(file.Matches, Match{Rule: re.String(), RuleType: RuleRegex, Line: line, Col: loc[0] + 1, Snippet: lineText})
+			}
+		}
+		line++
+	}
+
+	atomic.AddUint64(&s.Counter.SearchedFiles, 1)
+
+	if err := scanner.Err(); err != nil {
+		s.sendError(ctx, fmt.Sprintf("%s = %v", filePath, err))
+		return
+	}
+
+	if len(file.Matches) > 0 {
+		select {
+		case s.Output <- file:
+		case <-ctx.Done():
+		}
+	}
+}
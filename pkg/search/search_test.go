@@ -0,0 +1,81 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestSearcherRunFindsKeyword(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("needle here"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("nothing"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(dir, []string{"needle"}, nil, nil, 2, "")
+	var found []*FoundFile
+	done := make(chan struct{})
+	go func() {
+		for f := range s.Output {
+			found = append(found, f)
+		}
+		close(done)
+	}()
+	go func() {
+		for range s.Errors {
+		}
+	}()
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+
+	if len(found) != 1 {
+		t.Fatalf("found = %v, want exactly 1 match", found)
+	}
+	if s.Counter.FoundFiles != 2 {
+		t.Fatalf("FoundFiles = %d, want 2", s.Counter.FoundFiles)
+	}
+	if s.Counter.SearchedFiles != 2 {
+		t.Fatalf("SearchedFiles = %d, want 2", s.Counter.SearchedFiles)
+	}
+}
+
+func TestSearcherRunWithCandidates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("error: boom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(dir, nil, []*regexp.Regexp{regexp.MustCompile(`error: \w+`)}, nil, 1, "")
+	s.Candidates = []string{path}
+
+	var found []*FoundFile
+	done := make(chan struct{})
+	go func() {
+		for f := range s.Output {
+			found = append(found, f)
+		}
+		close(done)
+	}()
+	go func() {
+		for range s.Errors {
+		}
+	}()
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+
+	if len(found) != 1 {
+		t.Fatalf("found = %v, want exactly 1 match", found)
+	}
+}
@@ -0,0 +1,160 @@
+// Package status keeps an in-memory, concurrency-safe record of a GoFind
+// run's progress plus its most recent matches and errors, so the terminal
+// progress line and the optional HTTP API (pkg/httpapi) read from a single
+// source of truth instead of the counters being re-derived in two places.
+package status
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ian-Costa18/GoFind/pkg/search"
+)
+
+// Progress is a point-in-time snapshot of a run's counters, what
+// /rest/progress reports.
+type Progress struct {
+	FoundFiles    uint64        `json:"foundFiles"`
+	SearchedFiles uint64        `json:"searchedFiles"`
+	NumErrors     uint64        `json:"numErrors"`
+	NumIgnored    uint64        `json:"numIgnored"`
+	Elapsed       time.Duration `json:"elapsed"`
+}
+
+// Event is one update pushed to /rest/events subscribers as it happens.
+type Event struct {
+	Type  string            `json:"type"` // "match" or "error"
+	Match *search.FoundFile `json:"match,omitempty"`
+	Error string            `json:"error,omitempty"`
+}
+
+// Recorder layers ring buffers of recent matches/errors, and a fan-out of
+// live events, on top of a Searcher's counters.
+type Recorder struct {
+	start   time.Time
+	counter *search.NumFiles
+	cap     int
+
+	mu      sync.Mutex
+	errors  []string
+	matches []*search.FoundFile
+
+	subsMu sync.Mutex
+	subs   map[chan Event]struct{}
+}
+
+// NewRecorder returns a Recorder reporting counter's progress and keeping
+// up to capacity of the most recently recorded matches and errors.
+func NewRecorder(counter *search.NumFiles, capacity int) *Recorder {
+	return &Recorder{
+		start:   time.Now(),
+		counter: counter,
+		cap:     capacity,
+		subs:    make(map[chan Event]struct{}),
+	}
+}
+
+// Progress returns the current counters and elapsed time.
+func (r *Recorder) Progress() Progress {
+	return Progress{
+		FoundFiles:    atomic.LoadUint64(&r.counter.FoundFiles),
+		SearchedFiles: atomic.LoadUint64(&r.counter.SearchedFiles),
+		NumErrors:     atomic.LoadUint64(&r.counter.NumErrors),
+		NumIgnored:    atomic.LoadUint64(&r.counter.NumIgnored),
+		Elapsed:       time.Since(r.start),
+	}
+}
+
+// RecordMatch appends f to the recent-matches ring buffer and notifies any
+// /rest/events subscribers.
+func (r *Recorder) RecordMatch(f *search.FoundFile) {
+	r.mu.Lock()
+	r.matches = pushRing(r.matches, f, r.cap)
+	r.mu.Unlock()
+	r.publish(Event{Type: "match", Match: f})
+}
+
+// RecordError appends path to the recent-errors ring buffer and notifies
+// any /rest/events subscribers.
+func (r *Recorder) RecordError(path string) {
+	r.mu.Lock()
+	r.errors = pushRing(r.errors, path, r.cap)
+	r.mu.Unlock()
+	r.publish(Event{Type: "error", Error: path})
+}
+
+func pushRing[T any](buf []T, item T, capacity int) []T {
+	buf = append(buf, item)
+	if capacity > 0 && len(buf) > capacity {
+		buf = buf[len(buf)-capacity:]
+	}
+	return buf
+}
+
+// Matches returns up to the n most recently recorded matches, oldest
+// first. n <= 0 means "all of them".
+func (r *Recorder) Matches(n int) []*search.FoundFile {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return lastN(r.matches, n)
+}
+
+// Errors returns a page of recorded error paths, oldest first: offset
+// skips that many entries and limit caps how many come back (limit <= 0
+// means "the rest").
+func (r *Recorder) Errors(offset, limit int) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(r.errors) {
+		return nil
+	}
+	end := len(r.errors)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	out := make([]string, end-offset)
+	copy(out, r.errors[offset:end])
+	return out
+}
+
+func lastN[T any](items []T, n int) []T {
+	if n <= 0 || n > len(items) {
+		n = len(items)
+	}
+	out := make([]T, n)
+	copy(out, items[len(items)-n:])
+	return out
+}
+
+// Subscribe registers a new listener for live match/error events. cancel
+// must be called once the caller is done with the channel, to unregister
+// it and stop it leaking.
+func (r *Recorder) Subscribe() (events <-chan Event, cancel func()) {
+	c := make(chan Event, 16)
+	r.subsMu.Lock()
+	r.subs[c] = struct{}{}
+	r.subsMu.Unlock()
+	return c, func() {
+		r.subsMu.Lock()
+		delete(r.subs, c)
+		close(c)
+		r.subsMu.Unlock()
+	}
+}
+
+// publish fans e out to every live subscriber. A subscriber slow enough to
+// fill its buffer has the event dropped rather than blocking the search.
+func (r *Recorder) publish(e Event) {
+	r.subsMu.Lock()
+	defer r.subsMu.Unlock()
+	for c := range r.subs {
+		select {
+		case c <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ian-Costa18/GoFind/pkg/search"
+)
+
+func TestRecorderProgressReflectsCounter(t *testing.T) {
+	counter := &search.NumFiles{FoundFiles: 3, SearchedFiles: 2, NumErrors: 1, NumIgnored: 4}
+	rec := NewRecorder(counter, 10)
+
+	p := rec.Progress()
+	if p.FoundFiles != 3 || p.SearchedFiles != 2 || p.NumErrors != 1 || p.NumIgnored != 4 {
+		t.Fatalf("Progress() = %+v, want it to mirror the counter", p)
+	}
+	if p.Elapsed < 0 {
+		t.Fatalf("Elapsed = %v, want non-negative", p.Elapsed)
+	}
+}
+
+func TestRecorderMatchesRingBufferCaps(t *testing.T) {
+	rec := NewRecorder(&search.NumFiles{}, 2)
+	for i := 0; i < 5; i++ {
+		rec.RecordMatch(&search.FoundFile{FilePath: string(rune('a' + i))})
+	}
+	got := rec.Matches(10)
+	if len(got) != 2 {
+		t.Fatalf("Matches() = %v, want only the last 2 kept", got)
+	}
+	if got[0].FilePath != "d" || got[1].FilePath != "e" {
+		t.Fatalf("Matches() = %v, want [d e]", got)
+	}
+}
+
+func TestRecorderErrorsPagination(t *testing.T) {
+	rec := NewRecorder(&search.NumFiles{}, 10)
+	for _, p := range []string{"a", "b", "c", "d"} {
+		rec.RecordError(p)
+	}
+	if got := rec.Errors(1, 2); len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Fatalf("Errors(1, 2) = %v, want [b c]", got)
+	}
+	if got := rec.Errors(3, 0); len(got) != 1 || got[0] != "d" {
+		t.Fatalf("Errors(3, 0) = %v, want [d]", got)
+	}
+	if got := rec.Errors(10, 5); got != nil {
+		t.Fatalf("Errors(10, 5) = %v, want nil past the end", got)
+	}
+}
+
+func TestRecorderSubscribeReceivesLiveEvents(t *testing.T) {
+	rec := NewRecorder(&search.NumFiles{}, 10)
+	events, cancel := rec.Subscribe()
+	defer cancel()
+
+	rec.RecordMatch(&search.FoundFile{FilePath: "hit.txt"})
+	select {
+	case e := <-events:
+		if e.Type != "match" || e.Match.FilePath != "hit.txt" {
+			t.Fatalf("event = %+v, want a match event for hit.txt", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the subscribed event")
+	}
+}